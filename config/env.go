@@ -28,6 +28,13 @@ type Env struct {
 	VapidPublicKey string
 	VapidSubject string
 
+	// SMTP Config
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	SMTPFrom     string
+
 }
 
 func NewEnv(db *sqlx.DB, store *sessions.CookieStore, templates map[string]*template.Template) *Env {
@@ -54,6 +61,13 @@ func NewEnv(db *sqlx.DB, store *sessions.CookieStore, templates map[string]*temp
 		VapidPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
 		VapidPublicKey: os.Getenv("VAPID_PUBLIC_KEY"),
 		VapidSubject: os.Getenv("VAPID_SUBJECT"),
+
+		// Load SMTP Config
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUser:     os.Getenv("SMTP_USER"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     os.Getenv("SMTP_FROM"),
 	}
 }
 
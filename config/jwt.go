@@ -13,6 +13,8 @@ var (
 	PublicKey  *rsa.PublicKey
 	Issuer     string
 	Audience   string
+	// APIAudience adalah audience token akses API portal, berbeda dari token login (aud = slug aplikasi)
+	APIAudience string
 )
 
 func LoadKeys() error {
@@ -47,5 +49,10 @@ func LoadKeys() error {
 		return fmt.Errorf("JWT issuer kosong")
 	}
 
+	APIAudience = os.Getenv("JWT_API_AUDIENCE")
+	if APIAudience == "" {
+		APIAudience = Issuer + "-api"
+	}
+
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,287 @@
+// file: controllers/accessrequestcontroller/accessrequestcontroller.go
+
+package accessrequestcontroller
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/config"
+	"sso-portal-v5/middleware"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
+	"sso-portal-v5/views"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// AccessRequestController menangani pengajuan akses aplikasi oleh user dan keputusan oleh approver aplikasi.
+type AccessRequestController struct {
+	env   *config.Env
+	views *views.Views
+}
+
+func NewAccessRequestController(env *config.Env, v *views.Views) *AccessRequestController {
+	return &AccessRequestController{env: env, views: v}
+}
+
+// Index menampilkan katalog aplikasi yang dapat diajukan beserta riwayat pengajuan user.
+func (ac *AccessRequestController) Index(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	apps, err := models.GetRequestableApps(ac.env.DB, user, models.NewAccessContext(middleware.ClientIP(r)))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	requests, err := models.GetUserAccessRequests(ac.env.DB, user.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	pendingReviews, err := models.CountApproverPendingRequests(ac.env.DB, user.ID)
+	if err != nil {
+		log.Println("WARNING: Gagal menghitung pengajuan akses yang menunggu review:", err)
+	}
+
+	ac.views.RenderPage(w, r, "access-requests", map[string]interface{}{
+		"Apps":           apps,
+		"Requests":       requests,
+		"PendingReviews": pendingReviews,
+		"Flash":          ac.popFlash(w, r),
+	})
+}
+
+// Submit menyimpan pengajuan akses lalu mengirim push notification ke para approver aplikasi.
+func (ac *AccessRequestController) Submit(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	appID, _ := strconv.Atoi(r.FormValue("application_id"))
+	justification := strings.TrimSpace(r.FormValue("justification"))
+	if justification == "" {
+		ac.RenderError(w, r, http.StatusBadRequest, "Alasan pengajuan wajib diisi.")
+		return
+	}
+
+	apps, err := models.GetRequestableApps(ac.env.DB, user, models.NewAccessContext(middleware.ClientIP(r)))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	var app *models.RequestableApp
+	for i := range apps {
+		if apps[i].ID == appID {
+			app = &apps[i]
+			break
+		}
+	}
+	if app == nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Aplikasi ini tidak dapat diajukan.")
+		return
+	}
+	if app.HasPending {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan akses Anda untuk aplikasi ini masih menunggu keputusan.")
+		return
+	}
+
+	if _, err := models.CreateAccessRequest(ac.env.DB, app.ID, user.ID, justification); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	approvers, err := models.GetAppApprovers(ac.env.DB, app.ID)
+	if err != nil {
+		log.Println("WARNING: Gagal mengambil approver aplikasi:", err)
+	}
+	for _, approver := range approvers {
+		go services.SendPushNotification(
+			ac.env,
+			approver.UserID,
+			"Pengajuan Akses Aplikasi",
+			fmt.Sprintf("%s mengajukan akses ke %s.", user.Name, app.Name),
+			ac.env.BaseURL+"/access-requests/review",
+		)
+	}
+
+	ac.addFlash(w, r, "Pengajuan akses ke "+app.Name+" berhasil dikirim.")
+	http.Redirect(w, r, "/access-requests", http.StatusSeeOther)
+}
+
+// Review menampilkan pengajuan akses pada aplikasi yang approver-nya adalah user login.
+func (ac *AccessRequestController) Review(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+	if status == "all" {
+		status = ""
+	}
+
+	requests, err := models.GetApproverAccessRequests(ac.env.DB, user.ID, status)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	if status == "" {
+		status = "all"
+	}
+
+	ac.views.RenderPage(w, r, "access-requests-review", map[string]interface{}{
+		"Requests": requests,
+		"Status":   status,
+		"Today":    time.Now().Format("2006-01-02"),
+		"Flash":    ac.popFlash(w, r),
+	})
+}
+
+// Approve menyetujui pengajuan dan memberi akses sampai tanggal berakhir yang dipilih (opsional).
+func (ac *AccessRequestController) Approve(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	req, ok := ac.findReviewableRequest(w, r, user)
+	if !ok {
+		return
+	}
+
+	expiresAt := r.FormValue("expires_at")
+	if expiresAt != "" {
+		date, err := time.Parse("2006-01-02", expiresAt)
+		if err != nil || date.Format("2006-01-02") < time.Now().Format("2006-01-02") {
+			ac.RenderError(w, r, http.StatusBadRequest, "Tanggal berakhir akses tidak valid.")
+			return
+		}
+	}
+
+	// Blokir dari admin tidak dapat dibatalkan oleh approver
+	if err := models.ApproveAccessRequest(ac.env.DB, req.ID, user.ID, expiresAt, strings.TrimSpace(r.FormValue("note"))); err != nil {
+		if errors.Is(err, models.ErrAccessRequestBlocked) {
+			ac.RenderError(w, r, http.StatusBadRequest, "Pengguna ini diblokir administrator dari aplikasi tersebut dan tidak dapat disetujui.")
+			return
+		}
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan ini sudah diproses sebelumnya.")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	message := fmt.Sprintf("Akses Anda ke %s telah disetujui.", req.AppName)
+	if expiresAt != "" {
+		message = fmt.Sprintf("Akses Anda ke %s telah disetujui hingga %s.", req.AppName, expiresAt)
+	}
+	go services.SendPushNotification(ac.env, req.UserID, "Pengajuan Akses Disetujui", message, ac.env.BaseURL+"/dashboard")
+
+	ac.addFlash(w, r, "Pengajuan "+req.UserName+" untuk "+req.AppName+" disetujui.")
+	http.Redirect(w, r, "/access-requests/review", http.StatusSeeOther)
+}
+
+// Deny menolak pengajuan akses beserta alasannya.
+func (ac *AccessRequestController) Deny(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	req, ok := ac.findReviewableRequest(w, r, user)
+	if !ok {
+		return
+	}
+
+	if err := models.DenyAccessRequest(ac.env.DB, req.ID, user.ID, strings.TrimSpace(r.FormValue("note"))); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan ini sudah diproses sebelumnya.")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	go services.SendPushNotification(
+		ac.env,
+		req.UserID,
+		"Pengajuan Akses Ditolak",
+		fmt.Sprintf("Pengajuan akses Anda ke %s ditolak.", req.AppName),
+		ac.env.BaseURL+"/access-requests",
+	)
+
+	ac.addFlash(w, r, "Pengajuan "+req.UserName+" untuk "+req.AppName+" ditolak.")
+	http.Redirect(w, r, "/access-requests/review", http.StatusSeeOther)
+}
+
+// findReviewableRequest mengambil pengajuan dari URL dan memastikan user login adalah approver aplikasinya.
+// Approver tidak dapat memutuskan pengajuannya sendiri.
+func (ac *AccessRequestController) findReviewableRequest(w http.ResponseWriter, r *http.Request, user *models.FullUser) (*models.AccessRequest, bool) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	req, err := models.FindAccessRequestByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengajuan Tidak Ditemukan")
+			return nil, false
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return nil, false
+	}
+
+	isApprover, err := models.IsAppApprover(ac.env.DB, req.ApplicationID, user.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return nil, false
+	}
+	if !isApprover || req.UserID == user.ID {
+		ac.RenderError(w, r, http.StatusForbidden, "Anda tidak berwenang memproses pengajuan ini.")
+		return nil, false
+	}
+
+	if req.Status != "pending" {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan ini sudah diproses sebelumnya.")
+		return nil, false
+	}
+	return req, true
+}
+
+func (ac *AccessRequestController) addFlash(w http.ResponseWriter, r *http.Request, message string) {
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash(message)
+	session.Save(r, w)
+}
+
+func (ac *AccessRequestController) popFlash(w http.ResponseWriter, r *http.Request) string {
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	if len(flashes) > 0 {
+		return flashes[0].(string)
+	}
+	return ""
+}
+
+func (ac *AccessRequestController) RenderError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	w.WriteHeader(code)
+
+	data := map[string]interface{}{
+		"Code":    code,
+		"Message": message,
+	}
+
+	ac.views.RenderPage(w, r, "error", data)
+}
@@ -0,0 +1,22 @@
+package admincontroller
+
+import (
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+)
+
+// AccessMatrix menampilkan matriks aplikasi × role/jabatan dari aturan akses yang tersimpan.
+// Akses efektif per pengguna (termasuk kebijakan dan pengecualian) ada di halaman detail pengguna.
+func (ac *AdminController) AccessMatrix(w http.ResponseWriter, r *http.Request) {
+	matrix, err := models.GetAccessMatrix(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	ac.views.RenderPage(w, r, "admin-access-matrix", map[string]interface{}{
+		"Matrix": matrix,
+	})
+}
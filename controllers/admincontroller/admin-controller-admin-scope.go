@@ -0,0 +1,68 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AddAdminScope memberikan lingkup admin terdelegasi (jurusan atau prodi) kepada user.
+func (ac *AdminController) AddAdminScope(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if err := r.ParseForm(); err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Gagal Parsing Form")
+		return
+	}
+
+	user, err := models.FindUserByID(ac.env.DB, userID)
+	if err != nil || user == nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+		return
+	}
+	if user.Roles[0].Name == models.SuperAdminRole {
+		ac.RenderError(w, r, http.StatusBadRequest, "Admin utama sudah memiliki akses penuh.")
+		return
+	}
+
+	var majorID, prodiID *int
+	if id, _ := strconv.Atoi(r.FormValue("major_id")); id > 0 {
+		majorID = &id
+	} else if id, _ := strconv.Atoi(r.FormValue("study_program_id")); id > 0 {
+		prodiID = &id
+	} else {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pilih Jurusan atau Program Studi.")
+		return
+	}
+
+	if err := models.AddAdminScopeGrant(ac.env.DB, userID, majorID, prodiID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/user/detail/%d", userID), http.StatusSeeOther)
+}
+
+// DeleteAdminScope mencabut satu lingkup admin terdelegasi.
+func (ac *AdminController) DeleteAdminScope(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	userID, err := models.DeleteAdminScopeGrant(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Lingkup Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/user/detail/%d", userID), http.StatusSeeOther)
+}
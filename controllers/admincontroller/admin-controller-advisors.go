@@ -0,0 +1,140 @@
+package admincontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// SetUserAdvisor menetapkan, mengganti, atau melepas dosen wali seorang mahasiswa dari halaman detail user.
+func (ac *AdminController) SetUserAdvisor(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	if !ac.authorizeUserChange(w, r, id) {
+		return
+	}
+
+	user, err := models.FindUserByID(ac.env.DB, id)
+	if err != nil || user == nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+		return
+	}
+	if user.Student == nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Dosen wali hanya dapat ditetapkan untuk mahasiswa.")
+		return
+	}
+
+	advisorID, _ := strconv.Atoi(r.FormValue("advisor_id"))
+
+	if advisorID == 0 {
+		if err := models.RemoveStudentAdvisor(ac.env.DB, id); err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+	} else {
+		if !ac.validateAdvisor(w, r, advisorID) {
+			return
+		}
+		if err := models.SetStudentAdvisor(ac.env.DB, id, advisorID, admin.ID); err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/user/detail/%d", id), http.StatusSeeOther)
+}
+
+// AdvisorBulkForm menampilkan form penetapan dosen wali massal per prodi dan angkatan.
+func (ac *AdminController) AdvisorBulkForm(w http.ResponseWriter, r *http.Request) {
+	scope := adminScope(r)
+
+	prodis, err := models.GetAllStudyPrograms(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	lecturers, err := models.GetLecturerOptions(ac.env.DB, scope)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	ac.views.RenderPage(w, r, "admin-advisors-bulk", map[string]interface{}{
+		"Prodis":    scope.FilterStudyPrograms(prodis),
+		"Lecturers": lecturers,
+		"Flash":     flashMsg,
+	})
+}
+
+// BulkAssignAdvisors menetapkan satu dosen wali untuk seluruh mahasiswa di prodi dan angkatan yang dipilih.
+func (ac *AdminController) BulkAssignAdvisors(w http.ResponseWriter, r *http.Request) {
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	prodiID, _ := strconv.Atoi(r.FormValue("study_program_id"))
+	cohortYear, _ := strconv.Atoi(r.FormValue("cohort_year"))
+	advisorID, _ := strconv.Atoi(r.FormValue("advisor_id"))
+	onlyUnassigned := r.FormValue("only_unassigned") == "1"
+
+	if prodiID == 0 || cohortYear == 0 || advisorID == 0 {
+		ac.RenderError(w, r, http.StatusBadRequest, "Program Studi, Angkatan, dan Dosen Wali wajib diisi.")
+		return
+	}
+	if !adminScope(r).AllowsStudyProgram(prodiID) {
+		ac.RenderError(w, r, http.StatusForbidden, "Program Studi yang dipilih berada di luar lingkup kelola Anda.")
+		return
+	}
+	if !ac.validateAdvisor(w, r, advisorID) {
+		return
+	}
+
+	n, err := models.BulkAssignAdvisor(ac.env.DB, advisorID, prodiID, cohortYear, onlyUnassigned, admin.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash(fmt.Sprintf("Dosen wali berhasil ditetapkan untuk %d mahasiswa angkatan %d.", n, cohortYear))
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/advisors", http.StatusSeeOther)
+}
+
+// validateAdvisor memastikan dosen wali adalah dosen aktif dalam lingkup admin.
+// Menulis response error dan mengembalikan false jika tidak valid.
+func (ac *AdminController) validateAdvisor(w http.ResponseWriter, r *http.Request, advisorID int) bool {
+	ok, err := models.IsLecturerUser(ac.env.DB, advisorID)
+	if err == nil && ok {
+		ok, err = models.UserInScope(ac.env.DB, advisorID, adminScope(r))
+	}
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return false
+	}
+	if !ok {
+		ac.RenderError(w, r, http.StatusBadRequest, "Dosen wali harus dosen aktif dalam lingkup kelola Anda.")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package admincontroller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+)
+
+const (
+	// topAppsLimit adalah jumlah aplikasi pada grafik aplikasi terpopuler.
+	topAppsLimit = 10
+	// inactiveUsersLimit adalah jumlah user yang belum pernah login yang ditampilkan di dashboard.
+	inactiveUsersLimit = 20
+)
+
+// analyticsPeriods adalah pilihan periode statistik (hari).
+var analyticsPeriods = []int{7, 30, 90}
+
+// analyticsDays membaca periode statistik dari query ?days=, default 30 hari.
+func analyticsDays(r *http.Request) int {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	for _, p := range analyticsPeriods {
+		if p == days {
+			return days
+		}
+	}
+	return 30
+}
+
+// AnalyticsActivity mengembalikan tren harian user aktif, login, dan pembukaan aplikasi (JSON).
+func (ac *AdminController) AnalyticsActivity(w http.ResponseWriter, r *http.Request) {
+	trend, err := models.GetDailyActivity(ac.env.DB, analyticsDays(r))
+	ac.writeAnalytics(w, r, trend, err)
+}
+
+// AnalyticsTopApps mengembalikan aplikasi yang paling sering dibuka (JSON).
+func (ac *AdminController) AnalyticsTopApps(w http.ResponseWriter, r *http.Request) {
+	stats, err := models.GetTopApps(ac.env.DB, analyticsDays(r), topAppsLimit)
+	ac.writeAnalytics(w, r, stats, err)
+}
+
+// AnalyticsLaunches mengembalikan jumlah pembukaan aplikasi per role atau per kategori (?by=role|category, JSON).
+func (ac *AdminController) AnalyticsLaunches(w http.ResponseWriter, r *http.Request) {
+	days := analyticsDays(r)
+
+	var stats []models.LaunchBreakdown
+	var err error
+	switch r.URL.Query().Get("by") {
+	case "role":
+		stats, err = models.GetLaunchesByRole(ac.env.DB, days)
+	case "category":
+		stats, err = models.GetLaunchesByCategory(ac.env.DB, days)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Parameter by harus role atau category."})
+		return
+	}
+	ac.writeAnalytics(w, r, stats, err)
+}
+
+func (ac *AdminController) writeAnalytics(w http.ResponseWriter, r *http.Request, data interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Terjadi kesalahan pada sistem."})
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	json.NewEncoder(w).Encode(data)
+}
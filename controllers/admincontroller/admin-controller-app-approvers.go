@@ -0,0 +1,68 @@
+package admincontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// AddAppApprover menetapkan user (berdasarkan email) sebagai approver pengajuan akses aplikasi.
+// Aplikasi yang memiliki approver otomatis muncul pada katalog pengajuan akses.
+func (ac *AdminController) AddAppApprover(w http.ResponseWriter, r *http.Request) {
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+	redirectURL := fmt.Sprintf("/admin/application/detail/%d", app.ID)
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+
+	email := strings.TrimSpace(r.FormValue("email"))
+	user, err := models.FindUserByEmail(ac.env.DB, email)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if user == nil || user.Status != "aktif" {
+		session.AddFlash(fmt.Sprintf("Pengguna aktif dengan email %s tidak ditemukan.", email))
+		session.Save(r, w)
+		http.Redirect(w, r, redirectURL, http.StatusFound)
+		return
+	}
+
+	if err := models.AddAppApprover(ac.env.DB, app.ID, user.ID, admin.ID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session.AddFlash(user.Name + " ditetapkan sebagai approver.")
+	session.Save(r, w)
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// RemoveAppApprover mencabut approver dari aplikasi.
+func (ac *AdminController) RemoveAppApprover(w http.ResponseWriter, r *http.Request) {
+	appID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	userID, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+
+	if err := models.RemoveAppApprover(ac.env.DB, appID, userID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Approver berhasil dicabut.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", appID), http.StatusFound)
+}
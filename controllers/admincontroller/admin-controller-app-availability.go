@@ -0,0 +1,78 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// AddAvailabilityWindow menambahkan jadwal ketersediaan aplikasi, umum atau khusus untuk satu role.
+func (ac *AdminController) AddAvailabilityWindow(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	window := models.AvailabilityWindow{
+		ApplicationID: app.ID,
+		Label:         strings.TrimSpace(r.FormValue("label")),
+		RoleID:        formNullInt(r, "role_id"),
+	}
+	for field, target := range map[string]*sql.NullTime{"opens_at": &window.OpensAt, "closes_at": &window.ClosesAt} {
+		value := r.FormValue(field)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseInLocation("2006-01-02T15:04", value, time.Local)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusBadRequest, "Format tanggal jadwal tidak valid.")
+			return
+		}
+		*target = sql.NullTime{Time: parsed, Valid: true}
+	}
+
+	if err := models.ValidateAvailabilityWindow(window); err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Nama jadwal wajib diisi, jadwal umum harus memiliki tanggal, dan waktu tutup harus setelah waktu buka.")
+		return
+	}
+
+	if err := models.CreateAvailabilityWindow(ac.env.DB, window); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Jadwal ketersediaan berhasil ditambahkan.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", app.ID), http.StatusFound)
+}
+
+// DeleteAvailabilityWindow menghapus jadwal ketersediaan aplikasi.
+func (ac *AdminController) DeleteAvailabilityWindow(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	window, err := models.FindAvailabilityWindowByID(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Jadwal tidak ditemukan")
+		return
+	}
+
+	if err := models.DeleteAvailabilityWindow(ac.env.DB, window.ID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Jadwal ketersediaan berhasil dihapus.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", window.ApplicationID), http.StatusFound)
+}
@@ -0,0 +1,58 @@
+package admincontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AddDeepLinkPrefix menambahkan awalan path yang boleh dituju langsung melalui /redirect?app=...&path=...
+func (ac *AdminController) AddDeepLinkPrefix(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	prefix, err := models.NormalizeDeepLinkPrefix(r.FormValue("prefix"))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Awalan path harus diawali \"/\" dan tidak boleh berisi query, \"//\", backslash, atau segmen \"..\".")
+		return
+	}
+
+	if err := models.CreateDeepLinkPrefix(ac.env.DB, app.ID, prefix); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Awalan path deep link berhasil ditambahkan.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", app.ID), http.StatusFound)
+}
+
+// DeleteDeepLinkPrefix menghapus awalan path deep link aplikasi.
+func (ac *AdminController) DeleteDeepLinkPrefix(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	prefix, err := models.FindDeepLinkPrefixByID(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Awalan path tidak ditemukan")
+		return
+	}
+
+	if err := models.DeleteDeepLinkPrefix(ac.env.DB, prefix.ID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Awalan path deep link berhasil dihapus.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", prefix.ApplicationID), http.StatusFound)
+}
@@ -0,0 +1,53 @@
+package admincontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sso-portal-v5/models"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// UpdateAppMaintenance menyimpan URL health check dan mode pemeliharaan aplikasi.
+// Selama mode pemeliharaan aktif, pengguna yang membuka aplikasi melihat halaman pemeliharaan beserta pesannya.
+func (ac *AdminController) UpdateAppMaintenance(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	healthURL := strings.TrimSpace(r.FormValue("health_url"))
+	if healthURL != "" {
+		parsed, err := url.Parse(healthURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			ac.RenderError(w, r, http.StatusBadRequest, "URL health check harus berupa URL http atau https yang valid.")
+			return
+		}
+	}
+
+	maintenance := r.FormValue("maintenance_mode") == "1"
+	message := strings.TrimSpace(r.FormValue("maintenance_message"))
+	if len(message) > 255 {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pesan pemeliharaan maksimal 255 karakter.")
+		return
+	}
+
+	if err := models.UpdateAppMaintenance(ac.env.DB, app.ID, healthURL, maintenance, message); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	if maintenance {
+		session.AddFlash(app.Name + " sekarang dalam mode pemeliharaan.")
+	} else {
+		session.AddFlash("Pengaturan status aplikasi berhasil disimpan.")
+	}
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", app.ID), http.StatusFound)
+}
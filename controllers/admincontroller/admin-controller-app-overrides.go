@@ -0,0 +1,131 @@
+package admincontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// SetUserAppOverride menambahkan atau mengubah pengecualian akses aplikasi dari halaman detail user.
+func (ac *AdminController) SetUserAppOverride(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	if !ac.authorizeUserChange(w, r, id) {
+		return
+	}
+
+	user, err := models.FindUserByID(ac.env.DB, id)
+	if err != nil || user == nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+		return
+	}
+
+	effect := r.FormValue("effect")
+	if !models.IsValidOverrideEffect(effect) {
+		ac.RenderError(w, r, http.StatusBadRequest, "Jenis pengecualian tidak valid.")
+		return
+	}
+
+	appID := r.FormValue("application_id")
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, appID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	err = models.SetAppUserOverride(ac.env.DB, app.ID, user.ID, effect, strings.TrimSpace(r.FormValue("reason")), admin.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/user/detail/%d", user.ID), http.StatusSeeOther)
+}
+
+// SetAppOverrideByEmail menambahkan atau mengubah pengecualian akses user dari halaman detail aplikasi.
+func (ac *AdminController) SetAppOverrideByEmail(w http.ResponseWriter, r *http.Request) {
+	appID := mux.Vars(r)["id"]
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, appID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+	redirectURL := fmt.Sprintf("/admin/application/detail/%d", app.ID)
+
+	effect := r.FormValue("effect")
+	if !models.IsValidOverrideEffect(effect) {
+		ac.RenderError(w, r, http.StatusBadRequest, "Jenis pengecualian tidak valid.")
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+
+	email := strings.TrimSpace(r.FormValue("email"))
+	user, err := models.FindUserByEmail(ac.env.DB, email)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if user == nil {
+		session.AddFlash(fmt.Sprintf("Pengguna dengan email %s tidak ditemukan.", email))
+		session.Save(r, w)
+		http.Redirect(w, r, redirectURL, http.StatusFound)
+		return
+	}
+
+	if !ac.authorizeUserChange(w, r, user.ID) {
+		return
+	}
+
+	err = models.SetAppUserOverride(ac.env.DB, app.ID, user.ID, effect, strings.TrimSpace(r.FormValue("reason")), admin.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session.AddFlash(fmt.Sprintf("Pengecualian akses untuk %s berhasil disimpan.", user.Name))
+	session.Save(r, w)
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// DeleteAppOverride menghapus pengecualian akses. Field "from" menentukan halaman tujuan setelahnya
+// (detail aplikasi atau detail user).
+func (ac *AdminController) DeleteAppOverride(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	override, err := models.FindAppOverrideByID(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Pengecualian akses tidak ditemukan.")
+		return
+	}
+
+	if !ac.authorizeUserChange(w, r, override.UserID) {
+		return
+	}
+
+	if err := models.DeleteAppUserOverride(ac.env.DB, override.ID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	if r.FormValue("from") == "app" {
+		session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+		session.AddFlash("Pengecualian akses berhasil dihapus.")
+		session.Save(r, w)
+		http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", override.ApplicationID), http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/admin/user/detail/%d", override.UserID), http.StatusSeeOther)
+}
@@ -0,0 +1,59 @@
+package admincontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sso-portal-v5/models"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// UpdateAppTokenDelivery menyimpan cara token dikirim ke aplikasi (query string atau form POST otomatis)
+// endpoint yang diberi tahu saat user mencabut persetujuan, dan izin memanggil API mahasiswa bimbingan.
+func (ac *AdminController) UpdateAppTokenDelivery(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	delivery := r.FormValue("token_delivery")
+	if delivery != models.TokenDeliveryQuery && delivery != models.TokenDeliveryFormPost {
+		ac.RenderError(w, r, http.StatusBadRequest, "Cara pengiriman token tidak valid.")
+		return
+	}
+
+	// Token API tidak boleh muncul di URL, jadi akses API mahasiswa bimbingan hanya untuk aplikasi form POST
+	adviseeAPI := r.FormValue("advisee_api") == "1"
+	if adviseeAPI && delivery != models.TokenDeliveryFormPost {
+		ac.RenderError(w, r, http.StatusBadRequest, "Akses API mahasiswa bimbingan hanya dapat diberikan kepada aplikasi dengan pengiriman token Form POST.")
+		return
+	}
+
+	postURL := strings.TrimSpace(r.FormValue("token_post_url"))
+	revocationURL := strings.TrimSpace(r.FormValue("revocation_url"))
+	for _, endpoint := range []string{postURL, revocationURL} {
+		if endpoint == "" {
+			continue
+		}
+		parsed, err := url.Parse(endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(endpoint) > 255 {
+			ac.RenderError(w, r, http.StatusBadRequest, "Endpoint login dan endpoint pencabutan harus berupa URL http atau https yang valid.")
+			return
+		}
+	}
+
+	if err := models.UpdateAppTokenDelivery(ac.env.DB, app.ID, delivery, postURL, revocationURL, adviseeAPI); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Pengaturan integrasi token berhasil disimpan.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/detail/%d", app.ID), http.StatusFound)
+}
@@ -0,0 +1,373 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/gorilla/mux"
+)
+
+func (ac *AdminController) ListGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := models.GetAllGroups(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	_ = session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	ac.views.RenderPage(w, r, "admin-groups-list", map[string]interface{}{"Groups": groups, "Flash": flashMsg})
+}
+
+func (ac *AdminController) NewGroupForm(w http.ResponseWriter, r *http.Request) {
+	data, err := ac.groupFormMasters()
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	data["IsEdit"] = false
+
+	ac.views.RenderPage(w, r, "admin-groups-form", data)
+}
+
+func (ac *AdminController) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	form, ok := ac.parseGroupForm(w, r)
+	if !ok {
+		return
+	}
+
+	if err := models.CreateGroup(ac.env.DB, form.Name, form.Slug, form.Description, form.MembershipType, form.Rule); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			ac.RenderError(w, r, http.StatusBadRequest, "Slug grup sudah digunakan.")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Grup berhasil ditambahkan.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/admin/groups", http.StatusFound)
+}
+
+func (ac *AdminController) EditGroupForm(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	group, err := models.FindGroupByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Grup Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	data, err := ac.groupFormMasters()
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	data["Group"] = group
+	data["IsEdit"] = true
+
+	ac.views.RenderPage(w, r, "admin-groups-form", data)
+}
+
+func (ac *AdminController) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	form, ok := ac.parseGroupForm(w, r)
+	if !ok {
+		return
+	}
+
+	if err := models.UpdateGroup(ac.env.DB, id, form.Name, form.Slug, form.Description, form.MembershipType, form.Rule); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			ac.RenderError(w, r, http.StatusBadRequest, "Slug grup sudah digunakan.")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Grup berhasil diupdate.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/admin/groups", http.StatusFound)
+}
+
+func (ac *AdminController) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if err := models.DeleteGroup(ac.env.DB, id); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Grup Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Grup berhasil dihapus.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/admin/groups", http.StatusFound)
+}
+
+// DetailGroup menampilkan anggota grup beserta form penambahan anggota.
+func (ac *AdminController) DetailGroup(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	group, err := models.FindGroupByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Grup Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	// Anggota grup dinamis dihitung dari aturannya, dibatasi agar halaman tetap ringan
+	var members []models.GroupMember
+	memberCount := 0
+	if group.IsDynamic() {
+		memberCount, members, err = models.PreviewGroupMembers(ac.env.DB, group.GroupRule, dynamicGroupMemberLimit)
+	} else {
+		members, err = models.GetGroupMembers(ac.env.DB, id)
+		memberCount = len(members)
+	}
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	_ = session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	ac.views.RenderPage(w, r, "admin-groups-detail", map[string]interface{}{
+		"Group":       group,
+		"Members":     members,
+		"MemberCount": memberCount,
+		"Flash":       flashMsg,
+	})
+}
+
+// AddGroupMembers menambahkan anggota grup dari daftar email.
+func (ac *AdminController) AddGroupMembers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	group, err := models.FindGroupByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Grup Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if group.IsDynamic() {
+		ac.RenderError(w, r, http.StatusBadRequest, "Anggota grup dinamis ditentukan oleh aturannya dan tidak dapat ditambahkan manual.")
+		return
+	}
+
+	added, notFound, err := models.AddGroupMembersByEmail(ac.env.DB, id, r.FormValue("emails"), admin.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	msg := fmt.Sprintf("%d anggota berhasil ditambahkan.", added)
+	if len(notFound) > 0 {
+		msg += " Email tidak ditemukan: " + strings.Join(notFound, ", ")
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash(msg)
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/group/detail/%d", id), http.StatusSeeOther)
+}
+
+func (ac *AdminController) RemoveGroupMember(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	userID, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+
+	if err := models.RemoveGroupMember(ac.env.DB, id, userID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Anggota berhasil dikeluarkan dari grup.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/group/detail/%d", id), http.StatusSeeOther)
+}
+
+// PreviewGroup mengembalikan jumlah dan contoh anggota aturan grup dinamis dari form (JSON), sebelum disimpan.
+func (ac *AdminController) PreviewGroup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rule, err := parseGroupRule(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Aturan grup belum lengkap atau tidak valid."})
+		return
+	}
+
+	count, members, err := models.PreviewGroupMembers(ac.env.DB, rule, groupPreviewLimit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Terjadi kesalahan pada sistem."})
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	type previewMember struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	list := []previewMember{}
+	for _, m := range members {
+		list = append(list, previewMember{Name: m.Name, Email: m.Email, Role: m.RoleName})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":   count,
+		"members": list,
+	})
+}
+
+const (
+	groupPreviewLimit       = 20
+	dynamicGroupMemberLimit = 500
+)
+
+type groupForm struct {
+	Name           string
+	Slug           string
+	Description    string
+	MembershipType string
+	Rule           models.GroupRule
+}
+
+// parseGroupForm membaca dan memvalidasi form grup. Menulis response error dan mengembalikan false jika tidak valid.
+func (ac *AdminController) parseGroupForm(w http.ResponseWriter, r *http.Request) (groupForm, bool) {
+	form := groupForm{
+		Name:           strings.TrimSpace(r.FormValue("name")),
+		Slug:           strings.ToLower(strings.TrimSpace(r.FormValue("slug"))),
+		Description:    strings.TrimSpace(r.FormValue("description")),
+		MembershipType: "static",
+	}
+
+	if form.Name == "" || form.Slug == "" {
+		ac.RenderError(w, r, http.StatusBadRequest, "Nama dan Slug grup harus diisi.")
+		return form, false
+	}
+	if !models.IsValidGroupSlug(form.Slug) {
+		ac.RenderError(w, r, http.StatusBadRequest, "Slug hanya boleh berisi huruf kecil, angka, dan tanda hubung (contoh: tim-akreditasi).")
+		return form, false
+	}
+
+	if r.FormValue("membership_type") == "dynamic" {
+		rule, err := parseGroupRule(r)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusBadRequest, "Aturan grup dinamis belum lengkap: pilih sasaran dan unit untuk aturan yang dibatasi unit.")
+			return form, false
+		}
+		form.MembershipType = "dynamic"
+		form.Rule = rule
+	}
+	return form, true
+}
+
+// parseGroupRule membaca aturan grup dinamis dari form. Aturan berlingkup tanpa unit ditolak
+// agar tidak diam-diam berlaku untuk semua unit.
+func parseGroupRule(r *http.Request) (models.GroupRule, error) {
+	var rule models.GroupRule
+
+	target := r.FormValue("rule_target")
+	rule.Target = sql.NullString{String: target, Valid: target != ""}
+
+	switch r.FormValue("rule_scope") {
+	case "major":
+		id, _ := strconv.Atoi(r.FormValue("rule_major_id"))
+		if id <= 0 {
+			return rule, models.ErrIncompleteGroupRule
+		}
+		rule.MajorID = sql.NullInt64{Int64: int64(id), Valid: true}
+	case "prodi":
+		id, _ := strconv.Atoi(r.FormValue("rule_study_program_id"))
+		if id <= 0 {
+			return rule, models.ErrIncompleteGroupRule
+		}
+		rule.StudyProgramID = sql.NullInt64{Int64: int64(id), Valid: true}
+	}
+
+	if target == "mahasiswa" {
+		if year, _ := strconv.Atoi(r.FormValue("rule_cohort_year")); year > 0 {
+			rule.CohortYear = sql.NullInt64{Int64: int64(year), Valid: true}
+		}
+		if status := r.FormValue("rule_academic_status"); status != "" {
+			rule.AcademicStatus = sql.NullString{String: status, Valid: true}
+		}
+	}
+
+	if target == "dosen" && r.FormValue("rule_has_position") == "1" {
+		rule.HasPosition = true
+		if id, _ := strconv.Atoi(r.FormValue("rule_position_id")); id > 0 {
+			rule.PositionID = sql.NullInt64{Int64: int64(id), Valid: true}
+		}
+	}
+
+	return rule, models.ValidateGroupRule(rule)
+}
+
+// groupFormMasters mengambil data master untuk pilihan aturan grup dinamis.
+func (ac *AdminController) groupFormMasters() (map[string]interface{}, error) {
+	majors, prodis, err := ac.positionScopeMasters()
+	if err != nil {
+		return nil, err
+	}
+	positions, err := models.GetAllPositions(ac.env.DB)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"MasterMajors":     majors,
+		"MasterProdis":     prodis,
+		"MasterPositions":  positions,
+		"AcademicStatuses": models.AcademicStatuses,
+	}, nil
+}
@@ -0,0 +1,333 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/middleware"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ListAppPolicies menampilkan kebijakan akses bersyarat sebuah aplikasi sesuai urutan evaluasinya.
+func (ac *AdminController) ListAppPolicies(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	policies, err := models.GetAppPolicies(ac.env.DB, app.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	ac.views.RenderPage(w, r, "admin-policies-list", map[string]interface{}{
+		"App":      app,
+		"Policies": policies,
+		"Flash":    flashMsg,
+	})
+}
+
+func (ac *AdminController) NewPolicyForm(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	data, err := ac.policyFormMasters()
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	data["App"] = app
+	data["Policy"] = models.AppPolicy{Effect: "allow", Priority: 100, IsActive: true}
+	data["IsEdit"] = false
+
+	ac.views.RenderPage(w, r, "admin-policies-form", data)
+}
+
+func (ac *AdminController) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	policy, ok := ac.parsePolicyForm(w, r)
+	if !ok {
+		return
+	}
+	policy.ApplicationID = app.ID
+
+	if err := models.CreatePolicy(ac.env.DB, policy); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Kebijakan berhasil ditambahkan.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/policies/%d", app.ID), http.StatusFound)
+}
+
+func (ac *AdminController) EditPolicyForm(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	policy, err := models.FindPolicyByID(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Kebijakan tidak ditemukan")
+		return
+	}
+
+	app, _, _, err := models.FindApplicationByID(ac.env.DB, strconv.Itoa(policy.ApplicationID))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+		return
+	}
+
+	data, err := ac.policyFormMasters()
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	data["App"] = app
+	data["Policy"] = policy
+	data["IsEdit"] = true
+
+	ac.views.RenderPage(w, r, "admin-policies-form", data)
+}
+
+func (ac *AdminController) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	existing, err := models.FindPolicyByID(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Kebijakan tidak ditemukan")
+		return
+	}
+
+	policy, ok := ac.parsePolicyForm(w, r)
+	if !ok {
+		return
+	}
+	policy.ID = existing.ID
+
+	if err := models.UpdatePolicy(ac.env.DB, policy); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Kebijakan berhasil diupdate.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/policies/%d", existing.ApplicationID), http.StatusFound)
+}
+
+func (ac *AdminController) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	policy, err := models.FindPolicyByID(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusNotFound, "Kebijakan tidak ditemukan")
+		return
+	}
+
+	if err := models.DeletePolicy(ac.env.DB, policy.ID); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Kebijakan berhasil dihapus.")
+	session.Save(r, w)
+	http.Redirect(w, r, fmt.Sprintf("/admin/application/policies/%d", policy.ApplicationID), http.StatusFound)
+}
+
+// TestPolicy mengevaluasi akses seorang user ke sebuah aplikasi pada waktu dan IP tertentu,
+// lalu menjelaskan kebijakan atau aturan mana yang menentukan hasilnya.
+func (ac *AdminController) TestPolicy(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	apps, err := models.GetApplicationOptions(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	ip := strings.TrimSpace(q.Get("ip"))
+	if ip == "" {
+		ip = middleware.ClientIP(r)
+	}
+
+	ctx := models.NewAccessContext(ip)
+	if at := q.Get("at"); at != "" {
+		parsed, err := time.ParseInLocation("2006-01-02T15:04", at, time.Local)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusBadRequest, "Format waktu uji tidak valid.")
+			return
+		}
+		ctx.Time = parsed
+	}
+
+	data := map[string]interface{}{
+		"Apps":  apps,
+		"Email": q.Get("email"),
+		"AppID": q.Get("app_id"),
+		"IP":    ip,
+		"At":    ctx.Time.Format("2006-01-02T15:04"),
+	}
+
+	email := strings.TrimSpace(q.Get("email"))
+	appID, _ := strconv.Atoi(q.Get("app_id"))
+	if email != "" && appID > 0 {
+		user, err := models.FindUserByEmail(ac.env.DB, email)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+
+		if user == nil {
+			data["Error"] = "Pengguna dengan email " + email + " tidak ditemukan."
+		} else {
+			if !ac.authorizeUser(w, r, user.ID) {
+				return
+			}
+
+			decision, traces, err := models.ExplainAppAccess(ac.env.DB, user, appID, ctx)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
+					return
+				}
+				ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+				log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+				return
+			}
+			data["TestUser"] = user
+			data["Decision"] = decision
+			data["Traces"] = traces
+		}
+	}
+
+	ac.views.RenderPage(w, r, "admin-policies-test", data)
+}
+
+// parsePolicyForm membaca kebijakan dari form. Kondisi yang dikosongkan berarti "semua".
+func (ac *AdminController) parsePolicyForm(w http.ResponseWriter, r *http.Request) (models.AppPolicy, bool) {
+	r.ParseForm()
+
+	priority, err := strconv.Atoi(r.FormValue("priority"))
+	if err != nil {
+		priority = 100
+	}
+
+	policy := models.AppPolicy{
+		Name:     strings.TrimSpace(r.FormValue("name")),
+		Effect:   r.FormValue("effect"),
+		Priority: priority,
+		IsActive: r.FormValue("is_active") == "1",
+		RoleID:   formNullInt(r, "role_id"),
+		GroupID:  formNullInt(r, "group_id"),
+	}
+
+	if positionID := formNullInt(r, "position_id"); positionID.Valid {
+		policy.PositionID = positionID
+		switch r.FormValue("scope") {
+		case "major":
+			policy.MajorID = formNullInt(r, "major_id")
+			if !policy.MajorID.Valid {
+				ac.RenderError(w, r, http.StatusBadRequest, "Pilih jurusan untuk kondisi jabatan yang dibatasi jurusan.")
+				return policy, false
+			}
+		case "prodi":
+			policy.StudyProgramID = formNullInt(r, "study_program_id")
+			if !policy.StudyProgramID.Valid {
+				ac.RenderError(w, r, http.StatusBadRequest, "Pilih program studi untuk kondisi jabatan yang dibatasi prodi.")
+				return policy, false
+			}
+		}
+	}
+
+	if status := r.FormValue("academic_status"); status != "" {
+		policy.AcademicStatus = sql.NullString{String: status, Valid: true}
+	}
+	if days := models.ParsePolicyDays(r.Form["days"]); days != "" {
+		policy.DaysOfWeek = sql.NullString{String: days, Valid: true}
+	}
+	for field, target := range map[string]*sql.NullString{"start_time": &policy.StartTime, "end_time": &policy.EndTime} {
+		value := r.FormValue(field)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", value); err != nil {
+			ac.RenderError(w, r, http.StatusBadRequest, "Format jam tidak valid.")
+			return policy, false
+		}
+		*target = sql.NullString{String: value, Valid: true}
+	}
+
+	ipRanges, err := models.NormalizePolicyIPRanges(r.FormValue("ip_ranges"))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Rentang IP tidak valid. Gunakan IP atau CIDR (mis. 10.10.0.0/16) dipisah koma.")
+		return policy, false
+	}
+	if ipRanges != "" {
+		policy.IPRanges = sql.NullString{String: ipRanges, Valid: true}
+	}
+
+	if err := models.ValidatePolicy(policy); err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Nama, efek, dan kondisi kebijakan harus diisi dengan benar.")
+		return policy, false
+	}
+	return policy, true
+}
+
+func formNullInt(r *http.Request, field string) sql.NullInt64 {
+	id, _ := strconv.Atoi(r.FormValue(field))
+	return sql.NullInt64{Int64: int64(id), Valid: id > 0}
+}
+
+// policyFormMasters mengambil data master untuk pilihan kondisi kebijakan.
+func (ac *AdminController) policyFormMasters() (map[string]interface{}, error) {
+	data, err := ac.groupFormMasters()
+	if err != nil {
+		return nil, err
+	}
+	roles, err := models.GetAllRoles(ac.env.DB)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := models.GetAllGroups(ac.env.DB)
+	if err != nil {
+		return nil, err
+	}
+	data["MasterRoles"] = roles
+	data["MasterGroups"] = groups
+	data["Days"] = []struct {
+		Value int
+		Label string
+	}{{1, "Sen"}, {2, "Sel"}, {3, "Rab"}, {4, "Kam"}, {5, "Jum"}, {6, "Sab"}, {7, "Min"}}
+	return data, nil
+}
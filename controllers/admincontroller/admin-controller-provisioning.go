@@ -0,0 +1,185 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"regexp"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+func (ac *AdminController) ListProvisioningRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := models.GetAllProvisioningRules(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	ac.views.RenderPage(w, r, "admin-provisioning-list", map[string]interface{}{
+		"Rules": rules,
+		"Flash": flashMsg,
+	})
+}
+
+func (ac *AdminController) NewProvisioningRuleForm(w http.ResponseWriter, r *http.Request) {
+	roles, err := models.GetRegistrableRoles(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	ac.views.RenderPage(w, r, "admin-provisioning-form", map[string]interface{}{
+		"IsEdit": false,
+		"Roles":  roles,
+	})
+}
+
+func (ac *AdminController) CreateProvisioningRule(w http.ResponseWriter, r *http.Request) {
+	form, msg := ac.parseProvisioningRuleForm(r)
+	if msg != "" {
+		ac.RenderError(w, r, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := models.CreateProvisioningRule(ac.env.DB, form); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Aturan provisioning berhasil ditambahkan.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/admin/provisioning-rules", http.StatusFound)
+}
+
+func (ac *AdminController) EditProvisioningRuleForm(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	rule, err := models.FindProvisioningRuleByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Aturan Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	roles, err := models.GetRegistrableRoles(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	ac.views.RenderPage(w, r, "admin-provisioning-form", map[string]interface{}{
+		"IsEdit": true,
+		"Rule":   rule,
+		"Roles":  roles,
+	})
+}
+
+func (ac *AdminController) UpdateProvisioningRule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	form, msg := ac.parseProvisioningRuleForm(r)
+	if msg != "" {
+		ac.RenderError(w, r, http.StatusBadRequest, msg)
+		return
+	}
+	form.ID = id
+
+	if err := models.UpdateProvisioningRule(ac.env.DB, form); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Aturan Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Aturan provisioning berhasil diupdate.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/admin/provisioning-rules", http.StatusFound)
+}
+
+func (ac *AdminController) DeleteProvisioningRule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if err := models.DeleteProvisioningRule(ac.env.DB, id); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Aturan Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Aturan provisioning berhasil dihapus.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/admin/provisioning-rules", http.StatusFound)
+}
+
+// parseProvisioningRuleForm membaca dan memvalidasi input form aturan provisioning.
+// Mengembalikan pesan error untuk ditampilkan jika input tidak valid.
+func (ac *AdminController) parseProvisioningRuleForm(r *http.Request) (models.ProvisioningRuleForm, string) {
+	roleID, _ := strconv.Atoi(r.FormValue("role_id"))
+	priority, _ := strconv.Atoi(r.FormValue("priority"))
+
+	form := models.ProvisioningRuleForm{
+		Name:                 strings.TrimSpace(r.FormValue("name")),
+		Domain:               strings.TrimPrefix(strings.ToLower(strings.TrimSpace(r.FormValue("domain"))), "@"),
+		EmailPattern:         strings.TrimSpace(r.FormValue("email_pattern")),
+		RoleID:               roleID,
+		NIMPattern:           models.GetPtr(strings.TrimSpace(r.FormValue("nim_pattern"))),
+		RequiresVerification: r.FormValue("requires_verification") == "1",
+		IsActive:             r.FormValue("is_active") == "1",
+		Priority:             priority,
+	}
+
+	if form.Name == "" || form.Domain == "" || form.EmailPattern == "" || form.RoleID == 0 {
+		return form, "Nama, Domain, Pola Email, dan Role wajib diisi!"
+	}
+
+	if _, err := regexp.Compile(form.EmailPattern); err != nil {
+		return form, "Pola email bukan regex yang valid: " + err.Error()
+	}
+	if form.NIMPattern != nil {
+		if _, err := regexp.Compile(*form.NIMPattern); err != nil {
+			return form, "Pola NIM bukan regex yang valid: " + err.Error()
+		}
+	}
+
+	role, err := models.FindRoleByID(ac.env.DB, form.RoleID)
+	privileged, _ := models.IsPrivilegedRole(ac.env.DB, form.RoleID)
+	if err != nil || privileged {
+		return form, "Role yang dipilih tidak valid."
+	}
+	// Dosen wajib memiliki NIP atau NUPTK yang tidak dapat diturunkan dari email
+	if role.Name == "dosen" {
+		return form, "Aturan provisioning tidak dapat membuat akun Dosen. Gunakan pengajuan akun agar NIP/NUPTK terisi."
+	}
+	if role.Name == "mahasiswa" && form.NIMPattern == nil {
+		return form, "Pola NIM wajib diisi untuk aturan Mahasiswa!"
+	}
+
+	return form, ""
+}
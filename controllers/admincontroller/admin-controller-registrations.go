@@ -0,0 +1,173 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/gorilla/mux"
+)
+
+// ListRegistrations menampilkan antrian pengajuan pendaftaran mandiri.
+func (ac *AdminController) ListRegistrations(w http.ResponseWriter, r *http.Request) {
+	pageStr := r.URL.Query().Get("page")
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+	if status == "all" {
+		status = ""
+	}
+
+	page := 1
+	limit := 10
+
+	if pageStr != "" {
+		p, _ := strconv.Atoi(pageStr)
+		if p > 0 {
+			page = p
+		}
+	}
+
+	requests, err := models.GetRegistrationRequests(ac.env.DB, status, page, limit)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	if status == "" {
+		status = "all"
+	}
+
+	ac.views.RenderPage(w, r, "admin-registrations-list", map[string]interface{}{
+		"Requests": requests,
+		"Status":   status,
+		"Page":     page,
+		"Limit":    limit,
+		"Flash":    flashMsg,
+	})
+}
+
+// ApproveRegistration membuat akun pengguna dari pengajuan lalu memberi tahu pemohon.
+func (ac *AdminController) ApproveRegistration(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+
+	req, err := models.FindRegistrationRequestByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengajuan Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	if req.Status != "pending" {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan ini sudah diproses sebelumnya.")
+		return
+	}
+
+	if !ac.authorizeRoleAssignment(w, r, req.RoleID) {
+		return
+	}
+
+	userID, err := models.ApproveRegistrationRequest(ac.env.DB, req, admin.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan ini sudah diproses sebelumnya.")
+			return
+		}
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+			if mysqlErr.Number == 1062 {
+				ac.RenderError(w, r, http.StatusBadRequest, ac.duplicateUserMessage(req.Email, "Email, NIM, NIP, atau NUPTK sudah digunakan oleh pengguna lain."))
+				return
+			}
+		}
+
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	go services.SendPushNotification(
+		ac.env,
+		int(userID),
+		"Pendaftaran Disetujui",
+		"Akun Anda telah aktif. Silakan login ke Portal.",
+		ac.env.BaseURL,
+	)
+	go services.SendMail(
+		ac.env,
+		req.Email,
+		"Pendaftaran Portal SSO Disetujui",
+		fmt.Sprintf("Halo %s,\n\nPengajuan pendaftaran Anda sebagai %s telah disetujui.\nSilakan login melalui %s menggunakan akun Google kampus Anda.\n", req.Name, req.RoleName, ac.env.BaseURL),
+	)
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Pengajuan " + req.Name + " disetujui, akun berhasil dibuat.")
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/registrations", http.StatusSeeOther)
+}
+
+// RejectRegistration menolak pengajuan pendaftaran beserta alasannya.
+func (ac *AdminController) RejectRegistration(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	admin := r.Context().Value("UserLogin").(*models.FullUser)
+	note := r.FormValue("note")
+
+	req, err := models.FindRegistrationRequestByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengajuan Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	if err := models.ReviewRegistrationRequest(ac.env.DB, id, "rejected", admin.ID, note); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusBadRequest, "Pengajuan ini sudah diproses sebelumnya.")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	reason := note
+	if reason == "" {
+		reason = "-"
+	}
+	go services.SendMail(
+		ac.env,
+		req.Email,
+		"Pendaftaran Portal SSO Ditolak",
+		fmt.Sprintf("Halo %s,\n\nMohon maaf, pengajuan pendaftaran Anda belum dapat disetujui.\nAlasan: %s\n\nSilakan hubungi administrator untuk informasi lebih lanjut.\n", req.Name, reason),
+	)
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash("Pengajuan " + req.Name + " ditolak.")
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/registrations", http.StatusSeeOther)
+}
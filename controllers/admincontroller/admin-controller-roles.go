@@ -7,6 +7,7 @@ import (
 	"sso-portal-v5/models"
 	"strconv"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/gorilla/mux"
 )
 
@@ -18,15 +19,31 @@ func (ac *AdminController) ListRoles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	permissions, err := models.GetAllRolePermissions(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal Mengambil data Roles")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
 	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
 	flashes := session.Flashes()
 	_ = session.Save(r, w)
 
-	ac.views.RenderPage(w, r, "admin-roles-list", map[string]interface{}{"Roles": roles, "Flash": flashes})
+	ac.views.RenderPage(w, r, "admin-roles-list", map[string]interface{}{
+		"Roles":          roles,
+		"Permissions":    permissions,
+		"SuperAdminRole": models.SuperAdminRole,
+		"Flash":          flashes,
+	})
 }
 
 func (ac *AdminController) NewRoleForm(w http.ResponseWriter, r *http.Request) {
-	ac.views.RenderPage(w, r, "admin-roles-form", map[string]interface{}{"IsEdit": false})
+	ac.views.RenderPage(w, r, "admin-roles-form", map[string]interface{}{
+		"IsEdit":      false,
+		"Permissions": models.Permissions,
+		"Selected":    map[string]bool{},
+	})
 }
 
 func (ac *AdminController) CreateRole(w http.ResponseWriter, r *http.Request) {
@@ -38,12 +55,23 @@ func (ac *AdminController) CreateRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := models.CreateRole(ac.env.DB, name, desc); err != nil {
+	id, err := models.CreateRole(ac.env.DB, name, desc)
+	if err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			ac.RenderError(w, r, http.StatusBadRequest, "Nama role sudah digunakan, termasuk oleh role yang ada di Trash.")
+			return
+		}
 		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal simpan role")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
 		return
 	}
 
+	if err := models.SetRolePermissions(ac.env.DB, int(id), r.Form["permissions"]); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal simpan izin role")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
 	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
 	session.AddFlash("Role berhasil ditambahkan.")
 	session.Save(r, w)
@@ -63,9 +91,24 @@ func (ac *AdminController) EditRoleForm(w http.ResponseWriter, r *http.Request)
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
 		return
 	}
+
+	perms, err := models.GetRolePermissions(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal Mengambil data Role")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	selected := map[string]bool{}
+	for _, p := range perms {
+		selected[p] = true
+	}
+
 	ac.views.RenderPage(w, r, "admin-roles-form", map[string]interface{}{
-		"Role":   role,
-		"IsEdit": true,
+		"Role":         role,
+		"IsEdit":       true,
+		"Permissions":  models.Permissions,
+		"Selected":     selected,
+		"IsSuperAdmin": role.Name == models.SuperAdminRole,
 	})
 }
 
@@ -80,11 +123,21 @@ func (ac *AdminController) UpdateRole(w http.ResponseWriter, r *http.Request) {
 	desc := r.FormValue("description")
 
 	if err := models.UpdateRole(ac.env.DB, id, name, desc); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			ac.RenderError(w, r, http.StatusBadRequest, "Nama role sudah digunakan, termasuk oleh role yang ada di Trash.")
+			return
+		}
 		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal update role")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
 		return
 	}
 
+	if err := models.SetRolePermissions(ac.env.DB, id, r.Form["permissions"]); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal update izin role")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
 	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
 	session.AddFlash("Role berhasil diupdate.")
 	session.Save(r, w)
@@ -0,0 +1,108 @@
+package admincontroller
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ListTrash menampilkan data yang sudah dihapus (soft delete) per entitas.
+func (ac *AdminController) ListTrash(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("entity")
+	if key == "" {
+		key = models.TrashEntities[0].Key
+	}
+
+	entity, ok := models.FindTrashEntity(key)
+	if !ok {
+		ac.RenderError(w, r, http.StatusNotFound, "Jenis data tidak dikenal.")
+		return
+	}
+
+	items, err := models.GetTrashItems(ac.env.DB, entity)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	ac.views.RenderPage(w, r, "admin-trash", map[string]interface{}{
+		"Entities": models.TrashEntities,
+		"Entity":   entity,
+		"Items":    items,
+		"Flash":    flashMsg,
+	})
+}
+
+// RestoreTrash memulihkan data dari Trash.
+func (ac *AdminController) RestoreTrash(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+
+	entity, ok := models.FindTrashEntity(vars["entity"])
+	if !ok {
+		ac.RenderError(w, r, http.StatusNotFound, "Jenis data tidak dikenal.")
+		return
+	}
+
+	if err := models.RestoreTrashItem(ac.env.DB, entity, id); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Tidak Ditemukan di Trash")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash(entity.Label + " berhasil dipulihkan.")
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/trash?entity="+entity.Key, http.StatusSeeOther)
+}
+
+// PurgeTrash menghapus permanen data dari Trash.
+func (ac *AdminController) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.Atoi(vars["id"])
+
+	entity, ok := models.FindTrashEntity(vars["entity"])
+	if !ok {
+		ac.RenderError(w, r, http.StatusNotFound, "Jenis data tidak dikenal.")
+		return
+	}
+
+	if err := models.PurgeTrashItem(ac.env.DB, entity, id); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Tidak Ditemukan di Trash")
+			return
+		}
+		if err == models.ErrTrashInUse {
+			ac.RenderError(w, r, http.StatusConflict, entity.Label+" ini masih digunakan oleh data aktif. Lepaskan keterkaitannya sebelum menghapus permanen.")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash(entity.Label + " berhasil dihapus permanen.")
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/trash?entity="+entity.Key, http.StatusSeeOther)
+}
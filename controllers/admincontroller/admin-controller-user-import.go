@@ -0,0 +1,153 @@
+package admincontroller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
+)
+
+const importChunkSize = 100
+
+// ImportUserForm menampilkan halaman upload file import pengguna.
+func (ac *AdminController) ImportUserForm(w http.ResponseWriter, r *http.Request) {
+	ac.views.RenderPage(w, r, "admin-user-import", map[string]interface{}{})
+}
+
+// PreviewImportUser menjalankan dry-run: membaca file, memvalidasi setiap baris, dan menampilkan laporan
+// tanpa menyimpan apapun ke database.
+func (ac *AdminController) PreviewImportUser(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Ukuran file terlalu besar (Maks 10MB).")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "File import wajib diunggah.")
+		return
+	}
+	defer file.Close()
+
+	records, err := services.ReadSpreadsheet(file, header.Filename)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Gagal membaca file: "+err.Error())
+		return
+	}
+
+	rows, err := models.BuildImportRows(ac.env.DB, records)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Gagal memproses file: "+err.Error())
+		log.Printf("WARNING path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if err := ac.validateImportScope(r, rows); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	payload, _ := json.Marshal(records)
+
+	ac.views.RenderPage(w, r, "admin-user-import", map[string]interface{}{
+		"FileName":  header.Filename,
+		"Rows":      rows,
+		"HasErrors": models.HasImportErrors(rows),
+		"Payload":   string(payload),
+	})
+}
+
+// CommitImportUser memvalidasi ulang data hasil preview lalu menyimpannya per chunk.
+func (ac *AdminController) CommitImportUser(w http.ResponseWriter, r *http.Request) {
+	var records [][]string
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &records); err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Data import tidak valid, silakan unggah ulang file.")
+		return
+	}
+
+	rows, err := models.BuildImportRows(ac.env.DB, records)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Gagal memproses file: "+err.Error())
+		return
+	}
+	if err := ac.validateImportScope(r, rows); err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	if models.HasImportErrors(rows) {
+		ac.views.RenderPage(w, r, "admin-user-import", map[string]interface{}{
+			"FileName":  r.FormValue("file_name"),
+			"Rows":      rows,
+			"HasErrors": true,
+		})
+		return
+	}
+
+	result, err := models.ImportUsers(ac.env.DB, rows, importChunkSize)
+	if err != nil {
+		log.Printf("CRITICAL ERROR path=%s, line=%d, err=%v", r.URL.Path, result.FailedRow, err)
+		ac.RenderError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("Import berhenti pada baris %d. %d pengguna sudah tersimpan sebelum chunk yang gagal.", result.FailedRow, result.Imported))
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	session.AddFlash(fmt.Sprintf("%d pengguna berhasil diimport.", result.Imported))
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// validateImportScope menjalankan pemeriksaan yang sama dengan tambah user satuan pada setiap baris:
+// lingkup prodi/jabatan admin terdelegasi dan larangan memberi role berizin admin tanpa izin roles.write.
+func (ac *AdminController) validateImportScope(r *http.Request, rows []models.ImportRow) error {
+	access := adminAccess(r)
+	privileged := map[int]bool{}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Form.RoleID == 0 {
+			continue
+		}
+
+		if msg := validateUserScope(access.Scope, row.Form, nil); msg != "" {
+			row.Errors = append(row.Errors, msg)
+			continue
+		}
+
+		if access.Can(models.PermRolesWrite) {
+			continue
+		}
+		isPrivileged, ok := privileged[row.Form.RoleID]
+		if !ok {
+			var err error
+			isPrivileged, err = models.IsPrivilegedRole(ac.env.DB, row.Form.RoleID)
+			if err != nil {
+				return err
+			}
+			privileged[row.Form.RoleID] = isPrivileged
+		}
+		if isPrivileged {
+			row.Errors = append(row.Errors, "Anda tidak memiliki izin untuk mengelola pengguna dengan akses Administrator.")
+		}
+	}
+	return nil
+}
+
+// DownloadImportTemplate mengirim contoh file CSV untuk import pengguna.
+func (ac *AdminController) DownloadImportTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="template-import-user.csv"`)
+
+	writer := csv.NewWriter(w)
+	writer.Write(models.ImportColumns)
+	writer.Write([]string{"Budi Santoso", "budi@pnc.ac.id", "mahasiswa", "aktif", "220101001", "", "", "", "", "", "Teknik Informatika"})
+	writer.Write([]string{"Siti Aminah", "siti@pnc.ac.id", "dosen", "aktif", "", "198001012005012001", "0011223344", "", "",
+		"Kaprodi|prodi|Teknik Informatika|2024-01-01|2028-01-01", "Teknik Informatika"})
+	writer.Flush()
+}
@@ -8,11 +8,10 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"net/http"
+	"sso-portal-v5/middleware"
 	"sso-portal-v5/models"
 	"sso-portal-v5/services"
-	"strings"
 )
 
 type WebhookPayload struct {
@@ -37,7 +36,7 @@ func (ac *AdminController) HandleWebhook(w http.ResponseWriter, r *http.Request)
 	}
 	defer r.Body.Close()
 
-	senderIP := ac.getClientIP(r)
+	senderIP := middleware.ClientIP(r)
 	signature := r.Header.Get("X-Signature")
 	
 	if !ac.isValidSignature(bodyBytes, signature) {
@@ -133,22 +132,3 @@ func (ac *AdminController) isValidSignature(body []byte, signature string) bool
 
 	return hmac.Equal([]byte(signature), []byte(expectedSig))
 }
-
-// Helper: Ambil IP Client
-func (ac *AdminController) getClientIP(r *http.Request) string {
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return strings.Split(forwarded, ",")[0]
-	}
-
-	realIP := r.Header.Get("X-Real-Ip")
-	if realIP != "" {
-		return realIP
-	}
-
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return ip
-}
\ No newline at end of file
@@ -73,14 +73,13 @@ func (ac *AdminController) ListApplications(w http.ResponseWriter, r *http.Reque
 func (ac *AdminController) DetailApplication(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 
-	app, roleIDs, PosIDs, err := models.FindApplicationByID(ac.env.DB, id)
+	app, roleIDs, positionRules, err := models.FindApplicationByID(ac.env.DB, id)
 	if err != nil {
 		ac.RenderError(w, r, http.StatusNotFound, "Aplikasi tidak ditemukan")
 		return
 	}
 
 	var roleNames []string
-	var posNames []string
 	if len(roleIDs) > 0 {
 		query, args, _ := sqlx.In("SELECT role_name FROM roles WHERE id IN (?)", roleIDs)
 		query = ac.env.DB.Rebind(query)
@@ -92,21 +91,89 @@ func (ac *AdminController) DetailApplication(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	if len(PosIDs) > 0 {
-		query, args, _ := sqlx.In("SELECT position_name FROM positions WHERE id IN (?)", PosIDs)
-		query = ac.env.DB.Rebind(query)
-		err = ac.env.DB.Select(&posNames, query, args...)
-		if err != nil {
-			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
-			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
-			return
-		}
+	studentRules, err := models.GetStudentAccessRules(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	groups, err := models.GetApplicationGroups(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	overrides, err := models.GetAppUserOverrides(ac.env.DB, app.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	approvers, err := models.GetAppApprovers(ac.env.DB, app.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	health, err := models.GetAppHealth(ac.env.DB, app.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	healthHistory, err := models.GetAppHealthHistory(ac.env.DB, app.ID, 20)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	windows, err := models.GetAppAvailabilityWindows(ac.env.DB, app.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	deepLinkPrefixes, err := models.GetDeepLinkPrefixes(ac.env.DB, app.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
 	}
 
 	data := map[string]interface{}{
-		"App":            app,
-		"RoleNames":      roleNames,
-		"PositionsNames": posNames,
+		"App":           app,
+		"RoleNames":     roleNames,
+		"PositionRules": positionRules,
+		"StudentRules":  studentRules,
+		"Groups":        groups,
+		"Overrides":     overrides,
+		"Approvers":     approvers,
+		"Windows":       windows,
+		"DeepLinks":     deepLinkPrefixes,
+		"Health":        health,
+		"HealthHistory": healthHistory,
+		"Access":        adminAccess(r),
+		"Flash":         flashMsg,
+	}
+	if adminAccess(r).Can(models.PermAppsWrite) {
+		roles, _ := models.GetAllRoles(ac.env.DB)
+		data["MasterRoles"] = roles
 	}
 
 	ac.views.RenderPage(w, r, "admin-app-detail", data)
@@ -135,10 +202,28 @@ func (ac *AdminController) NewApplicationForm(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	majors, prodis, err := ac.positionScopeMasters()
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	groups, err := models.GetAllGroups(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
 	data := map[string]interface{}{
 		"Roles":    roles,
+		"Groups":   groups,
 		"Position": position,
 		"Categories": categories,
+		"MasterMajors": majors,
+		"MasterProdis": prodis,
+		"AcademicStatuses": models.AcademicStatuses,
 	}
 
 	ac.views.RenderPage(w, r, "admin-app-form", data)
@@ -159,7 +244,22 @@ func (ac *AdminController) CreateApplication(w http.ResponseWriter, r *http.Requ
 	categoryID, _ := strconv.Atoi(r.FormValue("category_id"))
 
 	roleIDs := r.Form["role_ids"]
-	posIDs := r.Form["position_ids"]
+	positionRules, err := models.ParsePositionAccessRules(r.FormValue("position_rules_json"))
+	if err == models.ErrIncompletePositionRule {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pilih jurusan/prodi untuk setiap aturan akses jabatan yang dibatasi unit.")
+		return
+	} else if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Format aturan akses jabatan tidak valid")
+		return
+	}
+	studentRules, err := models.ParseStudentAccessRules(r.FormValue("student_rules_json"))
+	if err == models.ErrIncompleteStudentRule {
+		ac.RenderError(w, r, http.StatusBadRequest, "Lengkapi aturan akses mahasiswa: pilih jurusan/prodi untuk aturan yang dibatasi unit.")
+		return
+	} else if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Format aturan akses mahasiswa tidak valid")
+		return
+	}
 
 	if name == "" || slug == "" || targetURL == "" {
 		ac.RenderError(w, r, http.StatusBadRequest, "Nama, Slug, dan Target URL harus diisi")
@@ -214,7 +314,7 @@ func (ac *AdminController) CreateApplication(w http.ResponseWriter, r *http.Requ
 		iconURL = "/uploads/icons/" + filename
 	}
 
-	err = models.CreateApplication(ac.env.DB, name, description, slug, targetURL, iconURL, categoryID, roleIDs, posIDs)
+	err = models.CreateApplication(ac.env.DB, name, description, slug, targetURL, iconURL, categoryID, roleIDs, positionRules, studentRules, r.Form["group_ids"])
 	if err != nil {
 		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
@@ -233,7 +333,7 @@ func (ac *AdminController) EditApplicationForm(w http.ResponseWriter, r *http.Re
 	id := mux.Vars(r)["id"]
 
 	// Ambil data aplikasi yang akan diedit
-	app, currentRoleIDs, currentPosIDs, err := models.FindApplicationByID(ac.env.DB, id)
+	app, currentRoleIDs, currentPositionRules, err := models.FindApplicationByID(ac.env.DB, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ac.RenderError(w, r, http.StatusNotFound, "Aplikasi Tidak Ditemukan")
@@ -270,18 +370,51 @@ func (ac *AdminController) EditApplicationForm(w http.ResponseWriter, r *http.Re
 		currentRolesMap[rid] = true
 	}
 
-	currentPosMap := make(map[int]bool)
-	for _, rid := range currentPosIDs {
-		currentPosMap[rid] = true
+	majors, prodis, err := ac.positionScopeMasters()
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	studentRules, err := models.GetStudentAccessRules(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	allGroups, err := models.GetAllGroups(ac.env.DB)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	currentGroups, err := models.GetApplicationGroups(ac.env.DB, id)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	currentGroupsMap := make(map[int]bool)
+	for _, g := range currentGroups {
+		currentGroupsMap[g.ID] = true
 	}
 
 	data := map[string]interface{}{
-		"App":              app,
-		"AllRoles":         allRoles,
-		"AllPositions":     allPos,
-		"CurrentRoles":     currentRolesMap,
-		"CurrentPositions": currentPosMap,
-		"Categories":       categories,
+		"App":                  app,
+		"AllRoles":             allRoles,
+		"AllGroups":            allGroups,
+		"CurrentGroups":        currentGroupsMap,
+		"AllPositions":         allPos,
+		"CurrentRoles":         currentRolesMap,
+		"CurrentPositionRules": models.PositionAccessRulesJSON(currentPositionRules),
+		"CurrentStudentRules":  models.StudentAccessRulesJSON(studentRules),
+		"AcademicStatuses":     models.AcademicStatuses,
+		"Categories":           categories,
+		"MasterMajors":         majors,
+		"MasterProdis":         prodis,
 	}
 
 	ac.views.RenderPage(w, r, "admin-app-edit", data)
@@ -304,7 +437,22 @@ func (ac *AdminController) UpdateApplication(w http.ResponseWriter, r *http.Requ
 	categoryID, _ := strconv.Atoi(r.FormValue("category_id"))
 
 	roleIDs := r.Form["role_ids"]
-	posIDs := r.Form["position_ids"]
+	positionRules, err := models.ParsePositionAccessRules(r.FormValue("position_rules_json"))
+	if err == models.ErrIncompletePositionRule {
+		ac.RenderError(w, r, http.StatusBadRequest, "Pilih jurusan/prodi untuk setiap aturan akses jabatan yang dibatasi unit.")
+		return
+	} else if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Format aturan akses jabatan tidak valid")
+		return
+	}
+	studentRules, err := models.ParseStudentAccessRules(r.FormValue("student_rules_json"))
+	if err == models.ErrIncompleteStudentRule {
+		ac.RenderError(w, r, http.StatusBadRequest, "Lengkapi aturan akses mahasiswa: pilih jurusan/prodi untuk aturan yang dibatasi unit.")
+		return
+	} else if err != nil {
+		ac.RenderError(w, r, http.StatusBadRequest, "Format aturan akses mahasiswa tidak valid")
+		return
+	}
 
 	if name == "" || slug == "" || targetURL == "" {
 		ac.RenderError(w, r, http.StatusBadRequest, "Nama, Slug. dan Target URL Harus Diisi.")
@@ -345,7 +493,7 @@ func (ac *AdminController) UpdateApplication(w http.ResponseWriter, r *http.Requ
 		iconURL = "/uploads/icons/" + filename
 	}
 
-	err = models.UpdateApplication(ac.env.DB, id, name, description, slug, targetURL, iconURL, categoryID, roleIDs, posIDs)
+	err = models.UpdateApplication(ac.env.DB, id, name, description, slug, targetURL, iconURL, categoryID, roleIDs, positionRules, studentRules, r.Form["group_ids"])
 	if err != nil {
 		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
@@ -380,3 +528,16 @@ func (ac *AdminController) DeleteApplication(w http.ResponseWriter, r *http.Requ
 
 	http.Redirect(w, r, "/admin/applications", http.StatusSeeOther)
 }
+
+// positionScopeMasters mengambil daftar jurusan dan prodi untuk pilihan lingkup aturan akses jabatan.
+func (ac *AdminController) positionScopeMasters() ([]models.Major, []models.StudyProgram, error) {
+	majors, err := models.GetAllMajors(ac.env.DB)
+	if err != nil {
+		return nil, nil, err
+	}
+	prodis, err := models.GetAllStudyPrograms(ac.env.DB)
+	if err != nil {
+		return nil, nil, err
+	}
+	return majors, prodis, nil
+}
@@ -21,7 +21,8 @@ func (ac *AdminController) ListStudyPrograms(w http.ResponseWriter, r *http.Requ
 	falshes := sessions.Flashes()
 	_ = sessions.Save(r, w)
 
-	ac.views.RenderPage(w, r, "admin-study-programs-list", map[string]interface{}{"Data": data, "Flash": falshes})
+	scope := adminScope(r)
+	ac.views.RenderPage(w, r, "admin-study-programs-list", map[string]interface{}{"Data": scope.FilterStudyPrograms(data), "Flash": falshes, "Scope": scope})
 }
 
 func (ac *AdminController) NewStudyProgramForm(w http.ResponseWriter, r *http.Request) {
@@ -33,7 +34,7 @@ func (ac *AdminController) NewStudyProgramForm(w http.ResponseWriter, r *http.Re
 	}
 
 	ac.views.RenderPage(w, r, "admin-study-programs-form", map[string]interface{}{
-		"Majors": majors,
+		"Majors": adminScope(r).FilterMajors(majors),
 		"IsEdit": false,
 	})
 }
@@ -53,6 +54,11 @@ func (ac *AdminController) CreateStudyProgram(w http.ResponseWriter, r *http.Req
 
 	majorID, _ := strconv.Atoi(r.FormValue("major_id"))
 
+	if !adminScope(r).AllowsMajor(majorID) {
+		ac.RenderError(w, r, http.StatusForbidden, "Jurusan yang dipilih berada di luar lingkup kelola Anda.")
+		return
+	}
+
 	if err := models.CreateStudyProgram(ac.env.DB, name, majorID); err != nil {
 		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal menyimpan prodi: "+err.Error())
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
@@ -82,6 +88,12 @@ func (ac *AdminController) EditStudyProgramForm(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	scope := adminScope(r)
+	if !scope.AllowsStudyProgram(prodi.ID) {
+		ac.RenderError(w, r, http.StatusForbidden, "Prodi ini berada di luar lingkup kelola Anda.")
+		return
+	}
+
 	majors, err := models.GetAllMajors(ac.env.DB)
 	if err != nil {
 		ac.RenderError(w, r, http.StatusInternalServerError, "Gagal mengambil data jurusan.")
@@ -91,7 +103,7 @@ func (ac *AdminController) EditStudyProgramForm(w http.ResponseWriter, r *http.R
 
 	ac.views.RenderPage(w, r, "admin-study-programs-form", map[string]interface{}{
 		"StudyProgram": prodi,
-		"Majors":       majors,
+		"Majors":       scopedMajorOptions(scope, majors, prodi.MajorID),
 		"IsEdit":       true,
 	})
 }
@@ -114,6 +126,10 @@ func (ac *AdminController) UpdateStudyProgram(w http.ResponseWriter, r *http.Req
 
 	majorID, _ := strconv.Atoi(r.FormValue("major_id"))
 
+	if !ac.authorizeStudyProgram(w, r, id, majorID) {
+		return
+	}
+
 	if err := models.UpdateStudyProgram(ac.env.DB, id, name, majorID); err != nil {
 		if err == sql.ErrNoRows {
 			ac.RenderError(w, r, http.StatusNotFound, "Data Prodi Tidak Ditemukan")
@@ -135,6 +151,10 @@ func (ac *AdminController) DeleteStudyProgram(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id, _ := strconv.Atoi(vars["id"])
 
+	if !ac.authorizeStudyProgram(w, r, id, 0) {
+		return
+	}
+
 	if err := models.DeleteStudyProgram(ac.env.DB, id); err != nil {
 		if err == sql.ErrNoRows {
 			ac.RenderError(w, r, http.StatusNotFound, "Data Prodi Tidak Ditemukan")
@@ -150,4 +170,49 @@ func (ac *AdminController) DeleteStudyProgram(w http.ResponseWriter, r *http.Req
 	session.Save(r, w)
 
 	http.Redirect(w, r, "/admin/study-programs", http.StatusFound)
-}
\ No newline at end of file
+}
+
+// authorizeStudyProgram memastikan prodi berada dalam lingkup admin. majorID adalah jurusan tujuan saat update
+// (0 untuk hapus); memindahkan prodi ke jurusan lain dan menghapus prodi memerlukan lingkup jurusan.
+// Mengembalikan false jika respon error sudah dikirim.
+func (ac *AdminController) authorizeStudyProgram(w http.ResponseWriter, r *http.Request, id, majorID int) bool {
+	scope := adminScope(r)
+	if scope.Global {
+		return true
+	}
+
+	prodi, err := models.FindStudyProgramByID(ac.env.DB, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Prodi Tidak Ditemukan")
+			return false
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return false
+	}
+
+	allowed := scope.AllowsStudyProgram(prodi.ID)
+	if majorID == 0 || majorID != prodi.MajorID {
+		allowed = allowed && scope.AllowsMajor(prodi.MajorID) && (majorID == 0 || scope.AllowsMajor(majorID))
+	}
+	if !allowed {
+		ac.RenderError(w, r, http.StatusForbidden, "Prodi ini berada di luar lingkup kelola Anda.")
+		return false
+	}
+	return true
+}
+
+// scopedMajorOptions menyaring pilihan jurusan sesuai lingkup, tetap menyertakan jurusan prodi saat ini.
+func scopedMajorOptions(scope *models.AdminScope, majors []models.Major, currentMajorID int) []models.Major {
+	if scope.Global {
+		return majors
+	}
+	var result []models.Major
+	for _, m := range majors {
+		if m.ID == currentMajorID || scope.AllowsMajor(m.ID) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
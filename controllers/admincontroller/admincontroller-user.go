@@ -37,7 +37,7 @@ func (ac *AdminController) ListUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	users, err := models.GetAllUsers(ac.env.DB, page, limit, search, role)
+	users, err := models.GetAllUsers(ac.env.DB, page, limit, search, role, adminScope(r))
 	if err != nil {
 		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
@@ -55,6 +55,7 @@ func (ac *AdminController) ListUsers(w http.ResponseWriter, r *http.Request) {
 		"Search": search,
 		"Role":   role,
 		"Flash":  flashes,
+		"Access": adminAccess(r),
 	}
 
 	ac.views.RenderPage(w, r, "admin-user-list", pageData)
@@ -69,9 +70,13 @@ func (ac *AdminController) DetailUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !ac.authorizeUser(w, r, id) {
+		return
+	}
+
 	user, err := models.FindUserByID(ac.env.DB, id)
-	if err != nil {
-		if err == sql.ErrNoRows {
+	if err != nil || user == nil {
+		if err == sql.ErrNoRows || user == nil {
 			ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
 			return
 		}
@@ -128,16 +133,90 @@ func (ac *AdminController) DetailUser(w http.ResponseWriter, r *http.Request) {
 		"Role":      role,
 		"Positions": positions,
 		"Profile":   profile,
+		"Access":    adminAccess(r),
+	}
+
+	if user.Student != nil {
+		data["AcademicStatus"] = models.AcademicStatusLabel(user.Student.AcademicStatus)
+
+		advisorID, advisorName, err := models.FindAdvisorName(ac.env.DB, user.ID)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		data["AdvisorID"] = advisorID
+		data["AdvisorName"] = advisorName
+
+		if adminAccess(r).Can(models.PermUsersWrite) {
+			lecturers, _ := models.GetLecturerOptions(ac.env.DB, adminScope(r))
+			data["LecturerOptions"] = lecturers
+		}
+	}
+
+	// Mahasiswa bimbingan (dosen wali)
+	if user.Lecturer != nil {
+		advisees, err := models.GetAdvisees(ac.env.DB, user.ID)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		data["Advisees"] = advisees
+	}
+
+	// Pengecualian akses aplikasi per user
+	overrides, err := models.GetUserAppOverrides(ac.env.DB, user.ID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	data["AppOverrides"] = overrides
+	if adminAccess(r).Can(models.PermAppAccessWrite) {
+		apps, _ := models.GetApplicationOptions(ac.env.DB)
+		data["AppOptions"] = apps
+	}
+
+	// Akses efektif: dievaluasi saat ini tanpa IP klien, sehingga kebijakan berbasis IP dianggap tidak cocok
+	if adminAccess(r).Can(models.PermAppsRead) {
+		effective, err := models.GetUserEffectiveAccess(ac.env.DB, user, models.NewAccessContext(""))
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		data["EffectiveAccess"] = effective
+	}
+
+	if prodiID := user.HomebaseStudyProgramID(); prodiID > 0 {
+		if prodi, err := models.FindStudyProgramByID(ac.env.DB, prodiID); err == nil {
+			data["Homebase"] = prodi.Name
+		}
+	}
+
+	// Pengaturan lingkup admin terdelegasi hanya untuk admin utama
+	if adminAccess(r).Can(models.PermRolesWrite) && role != models.SuperAdminRole {
+		grants, err := models.GetAdminScopeGrants(ac.env.DB, user.ID)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		majors, _ := models.GetAllMajors(ac.env.DB)
+		prodis, _ := models.GetAllStudyPrograms(ac.env.DB)
+
+		data["CanManageScope"] = true
+		data["ScopeGrants"] = grants
+		data["MasterMajors"] = majors
+		data["MasterProdis"] = prodis
 	}
 
 	ac.views.RenderPage(w, r, "admin-user-detail", data)
 }
 
 func (ac *AdminController) NewUserForm(w http.ResponseWriter, r *http.Request) {
-	roles, _ := models.GetAllRoles(ac.env.DB)
-	positions, _ := models.GetAllPositions(ac.env.DB)
-	majors, _ := models.GetAllMajors(ac.env.DB)
-	prodis, _ := models.GetAllStudyPrograms(ac.env.DB)
+	roles, positions, majors, prodis := ac.userFormMasters(adminAccess(r))
 
 	ac.views.RenderPage(w, r, "admin-user-form", map[string]interface{}{
 		"IsEdit":          false,
@@ -145,6 +224,8 @@ func (ac *AdminController) NewUserForm(w http.ResponseWriter, r *http.Request) {
 		"MasterPositions": positions,
 		"MasterMajors":    majors,
 		"MasterProdis":    prodis,
+		"HomebaseProdis":  prodis,
+		"AcademicStatuses": models.AcademicStatuses,
 	})
 }
 
@@ -170,6 +251,10 @@ func (ac *AdminController) CreateUser(w http.ResponseWriter, r *http.Request) {
 		NIP:      models.GetPtr(r.FormValue("nip")),
 		NUPTK:    models.GetPtr(r.FormValue("nuptk")),
 	}
+	if prodiID, _ := strconv.Atoi(r.FormValue("study_program_id")); prodiID > 0 {
+		form.StudyProgramID = &prodiID
+	}
+	parseStudentFields(r, &form)
 	if form.Status == "" {
 		form.Status = "active"
 	}
@@ -184,6 +269,10 @@ func (ac *AdminController) CreateUser(w http.ResponseWriter, r *http.Request) {
 			ac.RenderError(w, r, http.StatusBadRequest, "NIM Wajib diisi untuk Mahasiswa!")
 			return
 		}
+		if !models.IsValidAcademicStatus(form.AcademicStatus) {
+			ac.RenderError(w, r, http.StatusBadRequest, "Status akademik mahasiswa tidak valid.")
+			return
+		}
 	} else if form.RoleName == "dosen" {
 		if r.FormValue("nip") == "" || r.FormValue("nuptk") == "" {
 			ac.RenderError(w, r, http.StatusBadRequest, "Dosen wajib memiliki NIP atau NUPTK!")
@@ -199,11 +288,19 @@ func (ac *AdminController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 	form.Positions = positions
 
+	if msg := validateUserScope(adminScope(r), form, nil); msg != "" {
+		ac.RenderError(w, r, http.StatusForbidden, msg)
+		return
+	}
+	if !ac.authorizeRoleAssignment(w, r, form.RoleID) {
+		return
+	}
+
 	_, err = models.CreateUser(ac.env.DB, form)
 	if err != nil {
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
 			if mysqlErr.Number == 1062 {
-				ac.RenderError(w, r, http.StatusBadRequest, "Email sudah digunakan! Silahkan gunakan email lain.")
+				ac.RenderError(w, r, http.StatusBadRequest, ac.duplicateUserMessage(form.Email, "Email sudah digunakan! Silahkan gunakan email lain."))
 				return
 			}
 		}
@@ -222,20 +319,37 @@ func (ac *AdminController) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 func (ac *AdminController) EditUserForm(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if !ac.authorizeUserChange(w, r, id) || !ac.authorizePrivilegedUser(w, r, id) {
+		return
+	}
+
 	user, err := models.FindUserByID(ac.env.DB, id)
-	if err != nil {
+	if err != nil || user == nil {
 		http.Error(w, "User not found", 404)
 		return
 	}
 
-	roles, _ := models.GetAllRoles(ac.env.DB)
-	positions, _ := models.GetAllPositions(ac.env.DB)
-	majors, _ := models.GetAllMajors(ac.env.DB)
-	prodis, _ := models.GetAllStudyPrograms(ac.env.DB)
+	scope := adminScope(r)
+	roles, positions, majors, prodis := ac.userFormMasters(adminAccess(r))
+
+	// Prodi homebase lama tetap ditampilkan walau di luar lingkup, agar dosen yang masuk lingkup lewat jabatan tetap bisa diedit
+	homebaseProdis := prodis
+	if prodiID := user.HomebaseStudyProgramID(); prodiID > 0 && !scope.AllowsStudyProgram(prodiID) {
+		if prodi, err := models.FindStudyProgramByID(ac.env.DB, prodiID); err == nil {
+			homebaseProdis = append([]models.StudyProgram{*prodi}, prodis...)
+		}
+	}
+
+	history, err := ac.positionHistory(user)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
 
 	var existingPosJSON []models.PositionFormJSON
-	if user.Positions != nil {
-		for _, p := range user.Positions {
+	if history != nil {
+		for _, p := range history {
 			item := models.PositionFormJSON{
 				PositionID: p.PositionID,
 				Scope:      "none",
@@ -270,12 +384,17 @@ func (ac *AdminController) EditUserForm(w http.ResponseWriter, r *http.Request)
 		"MasterPositions":  positions,
 		"MasterMajors":     majors,
 		"MasterProdis":     prodis,
+		"HomebaseProdis":   homebaseProdis,
 		"CurrentPositions": string(posBytes),
+		"AcademicStatuses": models.AcademicStatuses,
 	})
 }
 
 func (ac *AdminController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if !ac.authorizeUserChange(w, r, id) || !ac.authorizePrivilegedUser(w, r, id) {
+		return
+	}
 	r.ParseForm()
 
 	roleID, _ := strconv.Atoi(r.FormValue("role_id"))
@@ -295,6 +414,10 @@ func (ac *AdminController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		NIP:      models.GetPtr(r.FormValue("nip")),
 		NUPTK:    models.GetPtr(r.FormValue("nuptk")),
 	}
+	if prodiID, _ := strconv.Atoi(r.FormValue("study_program_id")); prodiID > 0 {
+		form.StudyProgramID = &prodiID
+	}
+	parseStudentFields(r, &form)
 
 	if form.Name == "" || form.Email == "" || roleID == 0 {
 		ac.RenderError(w, r, http.StatusBadRequest, "Nama, Email, dan Role wajib diisi!")
@@ -306,6 +429,10 @@ func (ac *AdminController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 			ac.RenderError(w, r, http.StatusBadRequest, "NIM Wajib diisi untuk Mahasiswa!")
 			return
 		}
+		if !models.IsValidAcademicStatus(form.AcademicStatus) {
+			ac.RenderError(w, r, http.StatusBadRequest, "Status akademik mahasiswa tidak valid.")
+			return
+		}
 	} else if form.RoleName == "dosen" {
 		if r.FormValue("nip") == "" || r.FormValue("nuptk") == "" {
 			ac.RenderError(w, r, http.StatusBadRequest, "Dosen wajib memiliki NIP atau NUPTK!")
@@ -321,11 +448,34 @@ func (ac *AdminController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 	form.Positions = positions
 
+	scope := adminScope(r)
+	if !scope.Global {
+		existing, err := models.FindUserByID(ac.env.DB, id)
+		if err != nil || existing == nil {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+			return
+		}
+		// Form mengirim ulang seluruh riwayat jabatan, jadi pembandingnya juga riwayat lengkap
+		if existing.Positions, err = ac.positionHistory(existing); err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		if msg := validateUserScope(scope, form, existing); msg != "" {
+			ac.RenderError(w, r, http.StatusForbidden, msg)
+			return
+		}
+	}
+
+	if !ac.authorizeRoleAssignment(w, r, form.RoleID) {
+		return
+	}
+
 	err = models.UpdateUser(ac.env.DB, form)
 	if err != nil {
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
 			if mysqlErr.Number == 1062 {
-				ac.RenderError(w, r, http.StatusBadRequest, "Email sudah digunakan! Silahkan gunakan email lain.")
+				ac.RenderError(w, r, http.StatusBadRequest, ac.duplicateUserMessage(form.Email, "Email sudah digunakan! Silahkan gunakan email lain."))
 				return
 			}
 		}
@@ -342,6 +492,47 @@ func (ac *AdminController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
 }
 
+// UpdateUserStatus mengaktifkan atau menonaktifkan akun pengguna tanpa membuka form edit.
+func (ac *AdminController) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	status := r.FormValue("status")
+
+	if status != "aktif" && status != "nonaktif" {
+		ac.RenderError(w, r, http.StatusBadRequest, "Status tidak valid.")
+		return
+	}
+
+	loggedInUser := r.Context().Value("UserLogin").(*models.FullUser)
+	if loggedInUser.ID == id {
+		ac.RenderError(w, r, http.StatusBadRequest, "Anda tidak bisa mengubah status akun Anda sendiri!")
+		return
+	}
+
+	if !ac.authorizeUserChange(w, r, id) || !ac.authorizePrivilegedUser(w, r, id) {
+		return
+	}
+
+	if err := models.SetUserStatus(ac.env.DB, id, status); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+			return
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+	if status == "aktif" {
+		session.AddFlash("User dengan ID " + mux.Vars(r)["id"] + " berhasil diaktifkan.")
+	} else {
+		session.AddFlash("User dengan ID " + mux.Vars(r)["id"] + " berhasil dinonaktifkan.")
+	}
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
 func (ac *AdminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, _ := strconv.Atoi(vars["id"])
@@ -356,8 +547,17 @@ func (ac *AdminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+	if !ac.authorizeUserChange(w, r, id) || !ac.authorizePrivilegedUser(w, r, id) {
+		return
+	}
+
 	err := models.DeleteUser(ac.env.DB, id)
 	if err != nil {
+		// ID tidak dikenal atau user sudah berada di Trash
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+			return
+		}
 		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
 		return
@@ -369,3 +569,196 @@ func (ac *AdminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
 }
+
+// duplicateUserMessage memberi pesan yang lebih jelas saat bentrok unique key disebabkan user yang ada di Trash.
+func (ac *AdminController) duplicateUserMessage(email, fallback string) string {
+	if trashed, _ := models.IsEmailInTrash(ac.env.DB, email); trashed {
+		return "Email ini milik pengguna yang ada di Trash. Pulihkan atau hapus permanen pengguna tersebut terlebih dahulu."
+	}
+	return fallback
+}
+
+// authorizeUser memastikan user target berada dalam lingkup admin yang login.
+// Mengembalikan false jika respon error sudah dikirim.
+func (ac *AdminController) authorizeUser(w http.ResponseWriter, r *http.Request, id int) bool {
+	ok, err := models.UserInScope(ac.env.DB, id, adminScope(r))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return false
+	}
+	if !ok {
+		ac.RenderError(w, r, http.StatusForbidden, "Pengguna ini berada di luar lingkup kelola Anda.")
+		return false
+	}
+	return true
+}
+
+// authorizeUserChange seperti authorizeUser, tetapi untuk mengubah atau menghapus user: admin terdelegasi
+// hanya dapat mengelola user dengan prodi homebase di lingkupnya.
+func (ac *AdminController) authorizeUserChange(w http.ResponseWriter, r *http.Request, id int) bool {
+	ok, err := models.UserManageableInScope(ac.env.DB, id, adminScope(r))
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return false
+	}
+	if !ok {
+		ac.RenderError(w, r, http.StatusForbidden, "Pengguna ini berada di luar lingkup kelola Anda.")
+		return false
+	}
+	return true
+}
+
+// userFormMasters mengambil data master untuk form user, disaring sesuai hak akses admin.
+// Admin terdelegasi hanya dapat memilih role mahasiswa dan dosen, dan role berizin admin
+// hanya bisa dipilih oleh pemegang izin roles.write.
+func (ac *AdminController) userFormMasters(access *models.AdminAccess) ([]models.Role, []models.Position, []models.Major, []models.StudyProgram) {
+	scope := access.Scope
+	roles, _ := models.GetAllRoles(ac.env.DB)
+	positions, _ := models.GetAllPositions(ac.env.DB)
+	majors, _ := models.GetAllMajors(ac.env.DB)
+	prodis, _ := models.GetAllStudyPrograms(ac.env.DB)
+
+	if !scope.Global {
+		var scopedRoles []models.Role
+		for _, role := range roles {
+			if role.Name == "mahasiswa" || role.Name == "dosen" {
+				scopedRoles = append(scopedRoles, role)
+			}
+		}
+		roles = scopedRoles
+	}
+
+	if !access.Can(models.PermRolesWrite) {
+		var allowedRoles []models.Role
+		for _, role := range roles {
+			if privileged, err := models.IsPrivilegedRole(ac.env.DB, role.ID); err == nil && !privileged {
+				allowedRoles = append(allowedRoles, role)
+			}
+		}
+		roles = allowedRoles
+	}
+
+	return roles, positions, scope.FilterMajors(majors), scope.FilterStudyPrograms(prodis)
+}
+
+// validateUserScope memeriksa form user terhadap lingkup admin terdelegasi.
+// existing bernilai nil saat membuat user baru. Mengembalikan pesan error, kosong jika valid.
+func validateUserScope(scope *models.AdminScope, form models.UserForm, existing *models.FullUser) string {
+	if scope.Global {
+		return ""
+	}
+
+	if form.RoleName != "mahasiswa" && form.RoleName != "dosen" {
+		return "Anda hanya dapat mengelola pengguna dengan role Mahasiswa atau Dosen."
+	}
+
+	if form.StudyProgramID == nil {
+		return "Program Studi (Homebase) wajib diisi."
+	}
+	unchanged := existing != nil && existing.HomebaseStudyProgramID() == *form.StudyProgramID
+	if !unchanged && !scope.AllowsStudyProgram(*form.StudyProgramID) {
+		return "Program Studi yang dipilih berada di luar lingkup kelola Anda."
+	}
+
+	var current []models.LecturerPosition
+	if existing != nil {
+		current = existing.Positions
+	}
+
+	for _, pos := range form.Positions {
+		if !positionInScope(scope, pos) && !containsPosition(current, pos) {
+			return "Jabatan dengan unit di luar lingkup kelola Anda tidak dapat ditambahkan."
+		}
+	}
+
+	// Jabatan di luar lingkup yang sudah ada tidak boleh dilepas oleh admin terdelegasi
+	for _, pos := range current {
+		if !positionInScope(scope, pos) && !containsPosition(form.Positions, pos) {
+			return "Jabatan dengan unit di luar lingkup kelola Anda tidak dapat dihapus."
+		}
+	}
+
+	return ""
+}
+
+func positionInScope(scope *models.AdminScope, pos models.LecturerPosition) bool {
+	if pos.MajorID.Valid {
+		return scope.AllowsMajor(int(pos.MajorID.Int64))
+	}
+	if pos.StudyProgramID.Valid {
+		return scope.AllowsStudyProgram(int(pos.StudyProgramID.Int64))
+	}
+	return scope.Global
+}
+
+func containsPosition(list []models.LecturerPosition, pos models.LecturerPosition) bool {
+	for _, p := range list {
+		if p.PositionID == pos.PositionID && p.MajorID == pos.MajorID && p.StudyProgramID == pos.StudyProgramID {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizePrivilegedUser mencegah admin tanpa izin roles.write mengubah user yang memiliki akses admin.
+// Mengembalikan false jika respon error sudah dikirim.
+func (ac *AdminController) authorizePrivilegedUser(w http.ResponseWriter, r *http.Request, id int) bool {
+	if adminAccess(r).Can(models.PermRolesWrite) {
+		return true
+	}
+
+	var roleID int
+	if err := ac.env.DB.Get(&roleID, "SELECT role_id FROM user_roles WHERE user_id = ? LIMIT 1", id); err != nil {
+		if err == sql.ErrNoRows {
+			ac.RenderError(w, r, http.StatusNotFound, "Data Pengguna Tidak Ditemukan")
+			return false
+		}
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return false
+	}
+
+	return ac.authorizeRoleAssignment(w, r, roleID)
+}
+
+// authorizeRoleAssignment memastikan role berizin admin hanya diberikan oleh pemegang izin roles.write.
+// Mengembalikan false jika respon error sudah dikirim.
+func (ac *AdminController) authorizeRoleAssignment(w http.ResponseWriter, r *http.Request, roleID int) bool {
+	if adminAccess(r).Can(models.PermRolesWrite) {
+		return true
+	}
+
+	privileged, err := models.IsPrivilegedRole(ac.env.DB, roleID)
+	if err != nil {
+		ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return false
+	}
+	if privileged {
+		ac.RenderError(w, r, http.StatusForbidden, "Anda tidak memiliki izin untuk mengelola pengguna dengan akses Administrator.")
+		return false
+	}
+	return true
+}
+
+// positionHistory mengambil seluruh riwayat jabatan dosen, termasuk yang sudah berakhir.
+// FullUser.Positions hanya berisi jabatan yang sedang berlaku.
+func (ac *AdminController) positionHistory(user *models.FullUser) ([]models.LecturerPosition, error) {
+	if user.Lecturer == nil {
+		return nil, nil
+	}
+	return models.GetLecturerPositionHistory(ac.env.DB, user.Lecturer.ID)
+}
+
+// parseStudentFields membaca angkatan dan status akademik dari form user.
+func parseStudentFields(r *http.Request, form *models.UserForm) {
+	if year, _ := strconv.Atoi(r.FormValue("cohort_year")); year > 0 {
+		form.CohortYear = &year
+	}
+	form.AcademicStatus = r.FormValue("academic_status")
+	if form.AcademicStatus == "" {
+		form.AcademicStatus = "aktif"
+	}
+}
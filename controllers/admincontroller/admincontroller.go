@@ -1,8 +1,10 @@
 package admincontroller
 
 import (
+	"log"
 	"net/http"
 	"sso-portal-v5/config"
+	"sso-portal-v5/models"
 	"sso-portal-v5/views"
 )
 
@@ -15,11 +17,53 @@ func NewAdminController(env *config.Env, v *views.Views) *AdminController {
 	return &AdminController{env: env, views: v}
 }
 
-// Dashboard menampilkan halaman utama panel admin.
+// Dashboard menampilkan halaman utama panel admin beserta statistik penggunaan portal
+// (aplikasi untuk izin apps.read, user yang belum pernah login untuk izin users.read).
 func (ac *AdminController) Dashboard(w http.ResponseWriter, r *http.Request) {
+	access := adminAccess(r)
+	days := analyticsDays(r)
 
+	data := map[string]interface{}{
+		"Access":  access,
+		"Days":    days,
+		"Periods": analyticsPeriods,
+	}
 
-	ac.views.RenderPage(w, r, "admin-dashboard", map[string]interface{}{})
+	if access.Can(models.PermAppsRead) {
+		summary, err := models.GetAnalyticsSummary(ac.env.DB, days)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		data["Summary"] = summary
+	}
+
+	if access.Can(models.PermUsersRead) {
+		inactive, total, err := models.GetUsersNeverLoggedIn(ac.env.DB, adminScope(r), inactiveUsersLimit)
+		if err != nil {
+			ac.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		data["InactiveUsers"] = inactive
+		data["InactiveTotal"] = total
+	}
+
+	ac.views.RenderPage(w, r, "admin-dashboard", data)
+}
+
+// adminAccess mengambil hak akses panel admin dari context (diisi AdminAccessMiddleware).
+func adminAccess(r *http.Request) *models.AdminAccess {
+	if access, ok := r.Context().Value("AdminAccess").(*models.AdminAccess); ok {
+		return access
+	}
+	return &models.AdminAccess{Permissions: map[string]bool{}, Scope: &models.AdminScope{}}
+}
+
+// adminScope mengambil lingkup data admin yang login.
+func adminScope(r *http.Request) *models.AdminScope {
+	return adminAccess(r).Scope
 }
 
 func (ac *AdminController) RenderError(w http.ResponseWriter, r *http.Request, code int, message string) {
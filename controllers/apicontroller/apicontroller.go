@@ -0,0 +1,152 @@
+// file: controllers/apicontroller/apicontroller.go
+
+package apicontroller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sso-portal-v5/config"
+	"sso-portal-v5/models"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APIController melayani endpoint JSON untuk aplikasi klien. Autentikasi memakai token akses API
+// (api_token) yang dikirim portal lewat form POST kepada aplikasi yang diizinkan (header Authorization: Bearer <api_token>).
+// Token login tidak diterima karena audience-nya adalah slug aplikasi.
+type APIController struct {
+	env *config.Env
+}
+
+func NewAPIController(env *config.Env) *APIController {
+	return &APIController{env: env}
+}
+
+// adviseeResponse adalah data mahasiswa bimbingan yang dikirim ke aplikasi klien.
+type adviseeResponse struct {
+	UserID           int    `json:"user_id"`
+	Name             string `json:"name"`
+	Email            string `json:"email"`
+	NIM              string `json:"nim"`
+	StudyProgramID   int64  `json:"study_program_id,omitempty"`
+	StudyProgramName string `json:"study_program_name,omitempty"`
+	CohortYear       int64  `json:"cohort_year,omitempty"`
+	AcademicStatus   string `json:"academic_status"`
+}
+
+// ListAdvisees mengembalikan jumlah dan daftar mahasiswa bimbingan dosen pemilik token,
+// sehingga aplikasi klien tidak perlu menyimpan salinan relasi dosen wali sendiri.
+func (ac *APIController) ListAdvisees(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ac.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	isLecturer, err := models.IsLecturerUser(ac.env.DB, userID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Terjadi kesalahan pada sistem.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if !isLecturer {
+		writeJSONError(w, http.StatusForbidden, "Hanya dosen aktif yang memiliki mahasiswa bimbingan.")
+		return
+	}
+
+	advisees, err := models.GetAdvisees(ac.env.DB, userID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Terjadi kesalahan pada sistem.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	data := []adviseeResponse{}
+	for _, a := range advisees {
+		data = append(data, adviseeResponse{
+			UserID:           a.UserID,
+			Name:             a.Name,
+			Email:            a.Email,
+			NIM:              a.NIM,
+			StudyProgramID:   a.StudyProgramID.Int64,
+			StudyProgramName: a.StudyProgramName.String,
+			CohortYear:       a.CohortYear.Int64,
+			AcademicStatus:   a.AcademicStatus,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"advisor_id": userID,
+		"count":      len(data),
+		"advisees":   data,
+	})
+}
+
+// apiClaims adalah isi token akses API; azp adalah slug aplikasi penerima token.
+type apiClaims struct {
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// authenticate memverifikasi token akses API pada header Authorization dan mengembalikan user id (subject).
+// Aplikasi penerima token (azp) harus masih diizinkan memanggil API dan persetujuan user untuk aplikasi
+// tersebut belum dicabut. Menulis response error dan mengembalikan false jika token tidak valid.
+func (ac *APIController) authenticate(w http.ResponseWriter, r *http.Request) (int, bool) {
+	tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || tokenString == "" {
+		writeJSONError(w, http.StatusUnauthorized, "Token tidak ditemukan.")
+		return 0, false
+	}
+
+	claims := &apiClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return config.PublicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithIssuer(config.Issuer), jwt.WithAudience(config.APIAudience))
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "Token tidak valid atau sudah kedaluwarsa.")
+		return 0, false
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil || claims.AuthorizedParty == "" {
+		writeJSONError(w, http.StatusUnauthorized, "Token tidak valid.")
+		return 0, false
+	}
+
+	app, err := models.FindApplicationBySlug(ac.env.DB, claims.AuthorizedParty)
+	if err == sql.ErrNoRows {
+		writeJSONError(w, http.StatusUnauthorized, "Token tidak valid.")
+		return 0, false
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Terjadi kesalahan pada sistem.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return 0, false
+	}
+	if !app.AdviseeAPI {
+		writeJSONError(w, http.StatusForbidden, "Aplikasi tidak diizinkan memanggil API ini.")
+		return 0, false
+	}
+
+	consent, err := models.GetAppConsent(ac.env.DB, userID, app.ID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Terjadi kesalahan pada sistem.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return 0, false
+	}
+	if consent == nil || !consent.Includes(models.ClaimAdviseeAPI) {
+		writeJSONError(w, http.StatusForbidden, "Persetujuan akses data mahasiswa bimbingan sudah dicabut.")
+		return 0, false
+	}
+	return userID, true
+}
+
+func writeJSONError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
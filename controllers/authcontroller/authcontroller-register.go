@@ -0,0 +1,156 @@
+package authcontroller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
+	"strconv"
+	"strings"
+)
+
+// ShowRegisterForm menampilkan form pengajuan pendaftaran untuk email yang belum terdaftar.
+func (ac *AuthController) ShowRegisterForm(w http.ResponseWriter, r *http.Request) {
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+
+	email, ok := session.Values["register_email"].(string)
+	if !ok || email == "" {
+		session.AddFlash("Silakan login dengan akun Google kampus terlebih dahulu.")
+		session.Save(r, w)
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	name, _ := session.Values["register_name"].(string)
+
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	pending, err := models.FindPendingRegistrationByEmail(ac.env.DB, email)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	roles, err := models.GetRegistrableRoles(ac.env.DB)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	prodis, err := models.GetAllStudyPrograms(ac.env.DB)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"FlashMessages": flashes,
+		"Email":         email,
+		"Name":          name,
+		"Pending":       pending,
+		"Roles":         roles,
+		"Prodis":        prodis,
+	}
+
+	ac.env.Templates["register"].ExecuteTemplate(w, "register.html", data)
+}
+
+// SubmitRegistration menyimpan pengajuan pendaftaran ke antrian persetujuan admin.
+func (ac *AuthController) SubmitRegistration(w http.ResponseWriter, r *http.Request) {
+	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
+
+	email, ok := session.Values["register_email"].(string)
+	if !ok || email == "" {
+		session.AddFlash("Sesi pendaftaran berakhir, silakan login ulang.")
+		session.Save(r, w)
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Gagal parse form", http.StatusBadRequest)
+		return
+	}
+
+	fail := func(msg string) {
+		session.AddFlash(msg)
+		session.Save(r, w)
+		http.Redirect(w, r, "/register", http.StatusFound)
+	}
+
+	pending, err := models.FindPendingRegistrationByEmail(ac.env.DB, email)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if pending != nil {
+		fail("Pengajuan Anda masih menunggu persetujuan administrator.")
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	roleID, _ := strconv.Atoi(r.FormValue("role_id"))
+	if name == "" || roleID == 0 {
+		fail("Nama dan Peran wajib diisi!")
+		return
+	}
+
+	role, err := models.FindRoleByID(ac.env.DB, roleID)
+	privileged, _ := models.IsPrivilegedRole(ac.env.DB, roleID)
+	if err != nil || privileged {
+		fail("Peran yang dipilih tidak valid.")
+		return
+	}
+
+	form := models.RegistrationForm{
+		Name:   name,
+		Email:  email,
+		RoleID: roleID,
+	}
+
+	if prodiID, _ := strconv.Atoi(r.FormValue("study_program_id")); prodiID > 0 {
+		form.StudyProgramID = &prodiID
+	}
+
+	if role.Name == "mahasiswa" {
+		form.NIM = models.GetPtr(strings.TrimSpace(r.FormValue("nim")))
+		if form.NIM == nil {
+			fail("NIM Wajib diisi untuk Mahasiswa!")
+			return
+		}
+	} else if role.Name == "dosen" {
+		form.NIP = models.GetPtr(strings.TrimSpace(r.FormValue("nip")))
+		form.NUPTK = models.GetPtr(strings.TrimSpace(r.FormValue("nuptk")))
+		if form.NIP == nil || form.NUPTK == nil {
+			fail("Dosen wajib memiliki NIP atau NUPTK!")
+			return
+		}
+	}
+
+	if _, err := models.CreateRegistrationRequest(ac.env.DB, form); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	admin, err := models.FindUserByEmail(ac.env.DB, ac.env.AdminEmail)
+	if err == nil && admin != nil {
+		go services.SendPushNotification(
+			ac.env,
+			admin.ID,
+			"Pengajuan Pendaftaran Baru",
+			fmt.Sprintf("%s (%s) mengajukan pendaftaran sebagai %s", name, email, role.Name),
+			ac.env.BaseURL+"/admin/registrations",
+		)
+	}
+
+	log.Printf("INFO: Pengajuan pendaftaran baru dari %s", email)
+	session.AddFlash("Pengajuan berhasil dikirim. Anda akan menerima notifikasi setelah diproses administrator.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/register", http.StatusFound)
+}
@@ -5,11 +5,14 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"sso-portal-v5/config"
+	"sso-portal-v5/middleware"
 	"sso-portal-v5/models"
+	"sso-portal-v5/services"
 	"sso-portal-v5/views"
 	"strings"
 )
@@ -129,13 +132,47 @@ func (ac *AuthController) GoogleCallback(w http.ResponseWriter, r *http.Request)
 	}
 
 	if user == nil || user.ID == 0 {
+		trashed, err := models.IsEmailInTrash(ac.env.DB, userProfile.Email)
+		if err != nil {
+			http.Error(w, "Gagal mengambil detail user", http.StatusInternalServerError)
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+		if trashed {
+			session.AddFlash("Akun Anda telah dihapus. Silakan hubungi administrator.")
+			session.Save(r, w)
+			http.Redirect(w, r, "/", http.StatusFound)
+			log.Println("Akses ditolak, user sudah dihapus:", userProfile.Email)
+			return
+		}
+
+		user, err = ac.provisionUser(userProfile.Name, userProfile.Email)
+		if err != nil {
+			log.Printf("WARNING: Provisioning otomatis gagal untuk %s: %v", userProfile.Email, err)
+		}
+	}
+
+	if user == nil || user.ID == 0 {
+		// Simpan identitas Google yang sudah terverifikasi agar bisa mengajukan pendaftaran
+		session.Values["register_email"] = userProfile.Email
+		session.Values["register_name"] = userProfile.Name
+		delete(session.Values, "state")
+
 		session.AddFlash("Email Anda belum terdaftar di sistem.")
 		session.Save(r, w)
-		http.Redirect(w, r, "/", http.StatusFound)
+		http.Redirect(w, r, "/register", http.StatusFound)
 		log.Println("Akses ditolak, user tidak ditemukan:", userProfile.Email)
 		return
 	}
 
+	if user.Status == "pending" {
+		session.AddFlash("Akun Anda sedang menunggu verifikasi administrator.")
+		session.Save(r, w)
+		http.Redirect(w, r, "/", http.StatusFound)
+		log.Println("Akses ditolak, user menunggu verifikasi:", userProfile.Email)
+		return
+	}
+
 	if user.Status != "aktif" {
 		session.AddFlash("Akun Anda tidak aktif. Silakan hubungi administrator.")
 		session.Save(r, w)
@@ -171,10 +208,45 @@ func (ac *AuthController) GoogleCallback(w http.ResponseWriter, r *http.Request)
 		http.Redirect(w, r, "/", http.StatusFound)
 		return
 	}
+
+	if err := models.RecordLogin(ac.env.DB, user.ID, user.Roles[0].RoleID, middleware.ClientIP(r), r.UserAgent()); err != nil {
+		log.Printf("WARNING: Gagal mencatat login user %d: %v", user.ID, err)
+	}
+
 	session.Save(r, w)
 	http.Redirect(w, r, "/dashboard", http.StatusFound)
 }
 
+// provisionUser membuat akun otomatis berdasarkan aturan provisioning yang cocok dengan email.
+// Mengembalikan nil jika tidak ada aturan yang cocok.
+func (ac *AuthController) provisionUser(name, email string) (*models.FullUser, error) {
+	rule, err := models.MatchProvisioningRule(ac.env.DB, email)
+	if err != nil || rule == nil {
+		return nil, err
+	}
+
+	userID, err := models.CreateUser(ac.env.DB, rule.ToUserForm(name, email))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INFO: User %s dibuat otomatis oleh aturan provisioning '%s'", email, rule.Name)
+
+	if rule.RequiresVerification {
+		admin, err := models.FindUserByEmail(ac.env.DB, ac.env.AdminEmail)
+		if err == nil && admin != nil {
+			go services.SendPushNotification(
+				ac.env,
+				admin.ID,
+				"Akun Baru Menunggu Verifikasi",
+				fmt.Sprintf("%s (%s) dibuat otomatis sebagai %s", name, email, rule.RoleName),
+				fmt.Sprintf("%s/admin/user/detail/%d", ac.env.BaseURL, userID),
+			)
+		}
+	}
+
+	return models.FindUserByID(ac.env.DB, int(userID))
+}
+
 // Logout menghapus session pengguna
 func (ac *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
 	session, _ := ac.env.Store.Get(r, ac.env.SessionName)
@@ -0,0 +1,55 @@
+package dashboardcontroller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sso-portal-v5/middleware"
+	"sso-portal-v5/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AddFavorite menyematkan aplikasi ke baris favorit dashboard user (JSON).
+// Hanya aplikasi yang dapat diakses user yang dapat dijadikan favorit.
+func (dc *DashboardController) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+	appID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	access, err := models.CanAccessApp(dc.env.DB, user, appID, models.NewAccessContext(middleware.ClientIP(r)))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !access.Decision.Allowed) {
+		dc.writeFavorite(w, http.StatusForbidden, map[string]interface{}{"error": "Anda tidak memiliki akses ke aplikasi ini."})
+		return
+	}
+	if err == nil {
+		err = models.AddFavoriteApp(dc.env.DB, user.ID, appID)
+	}
+	if err != nil {
+		dc.writeFavorite(w, http.StatusInternalServerError, map[string]interface{}{"error": "Terjadi kesalahan pada sistem."})
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	dc.writeFavorite(w, http.StatusOK, map[string]interface{}{"favorite": true})
+}
+
+// RemoveFavorite melepas aplikasi dari baris favorit dashboard user (JSON).
+func (dc *DashboardController) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+	appID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if err := models.RemoveFavoriteApp(dc.env.DB, user.ID, appID); err != nil {
+		dc.writeFavorite(w, http.StatusInternalServerError, map[string]interface{}{"error": "Terjadi kesalahan pada sistem."})
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	dc.writeFavorite(w, http.StatusOK, map[string]interface{}{"favorite": false})
+}
+
+func (dc *DashboardController) writeFavorite(w http.ResponseWriter, code int, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
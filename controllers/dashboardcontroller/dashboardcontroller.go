@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"sso-portal-v5/config"
+	"sso-portal-v5/middleware"
 	"sso-portal-v5/models"
 	"sso-portal-v5/views"
 )
@@ -24,18 +25,12 @@ func NewDashboardController(env *config.Env, v *views.Views) *DashboardControlle
 	}
 }
 
+// recentAppsLimit adalah jumlah aplikasi pada bagian "Terakhir Dibuka".
+const recentAppsLimit = 6
+
 func (dc *DashboardController) Index(w http.ResponseWriter, r *http.Request) {
 
 	user := r.Context().Value("UserLogin").(*models.FullUser)
-	role := user.Roles[0].Name
-
-	positionsToQuery := []int{}
-
-	if role == "dosen" {
-		for _, pos := range user.Positions {
-			positionsToQuery = append(positionsToQuery, pos.PositionID)
-		}
-	}
 
 	allCategories, err := models.GetAllCategories(dc.env.DB)
 	if err != nil {
@@ -53,12 +48,34 @@ func (dc *DashboardController) Index(w http.ResponseWriter, r *http.Request) {
         activeCatID = allCategories[0].ID
     }
 
-	apps, err := models.FindAccessibleApps(dc.env.DB, role, positionsToQuery, activeCatID)
+	// Semua aplikasi yang dapat diakses dipakai untuk pencarian lintas kategori, favorit, dan terakhir dibuka
+	allApps, err := models.FindAllAccessibleApps(dc.env.DB, user, models.NewAccessContext(middleware.ClientIP(r)))
 	if err != nil {
 		dc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
 		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
 		return
 	}
+	if allApps == nil {
+		allApps = []models.Application{}
+	}
+
+	var apps []models.Application
+	for _, app := range allApps {
+		if app.CategoryID == activeCatID {
+			apps = append(apps, app)
+		}
+	}
+
+	favoriteIDs, err := models.GetFavoriteAppIDs(dc.env.DB, user.ID)
+	if err != nil {
+		log.Println("WARNING: Gagal mengambil aplikasi favorit user:", err)
+	}
+	recentIDs, err := models.GetRecentAppIDs(dc.env.DB, user.ID, recentAppsLimit)
+	if err != nil {
+		log.Println("WARNING: Gagal mengambil aplikasi terakhir dibuka user:", err)
+	}
+	favorites := models.PickApps(allApps, favoriteIDs)
+	recent := models.PickApps(allApps, recentIDs)
 
 	adminContact, err := models.GetContact(dc.env.DB, dc.env.AdminEmail)
 	if err != nil {
@@ -85,21 +102,34 @@ func (dc *DashboardController) Index(w http.ResponseWriter, r *http.Request) {
 		}
 
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"Apps":   apps,
-			"Notifs": notifications,
+			"Apps":      apps,
+			"AllApps":   allApps,
+			"Favorites": favorites,
+			"Recent":    recent,
+			"Notifs":    notifications,
 		})
 		return 
 	}
 
 	vapidpublickey := dc.env.VapidPublicKey
 
+	// Role dengan izin admin dan admin terdelegasi (jurusan/prodi) juga mendapat akses ke panel admin
+	adminAccess, err := models.LoadAdminAccess(dc.env.DB, user)
+	if err != nil {
+		log.Println("WARNING: Gagal mengambil hak akses admin user:", err)
+	}
+
 	dc.views.RenderPage(w, r, "dashboard", map[string]interface{}{
 		"Apps":  apps,
+		"AllApps":   allApps,
+		"Favorites": favorites,
+		"Recent":    recent,
 		"Admin": adminContact,
 		"Notifs": notifications,
 		"VapidPublicKey": vapidpublickey,
 		"Categories": allCategories,
 		"ActiveCatID":    activeCatID,
+		"HasAdminAccess": adminAccess != nil,
 	})
 
 }
@@ -0,0 +1,141 @@
+package redirectcontroller
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"sso-portal-v5/middleware"
+	"sso-portal-v5/models"
+)
+
+// ConsentItem adalah satu baris data yang akan dikirim ke aplikasi pada layar persetujuan.
+type ConsentItem struct {
+	Label string
+	Value string
+}
+
+// claimKeys mengembalikan nama klaim berisi data pribadi yang dikirim ke aplikasi, terurut.
+// Path deep link tidak termasuk karena bukan data user.
+func claimKeys(c *Claims) []string {
+	keys := []string{"name", "email", "avatar", "role"}
+	for key := range c.Profile {
+		keys = append(keys, key)
+	}
+	if len(c.Positions) > 0 {
+		keys = append(keys, "positions")
+	}
+	if len(c.Groups) > 0 {
+		keys = append(keys, "groups")
+	}
+	if c.AdviseeAPI {
+		keys = append(keys, models.ClaimAdviseeAPI)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// claimFingerprint adalah daftar klaim yang disimpan saat user menyetujui; persetujuan diminta ulang jika berubah.
+func claimFingerprint(c *Claims) string {
+	return strings.Join(claimKeys(c), ",")
+}
+
+// consentItems menyusun data yang ditampilkan pada layar persetujuan beserta nilainya.
+func consentItems(c *Claims) []ConsentItem {
+	var items []ConsentItem
+	for _, key := range claimKeys(c) {
+		var value string
+		switch key {
+		case "name":
+			value = c.Name
+		case "email":
+			value = c.Email
+		case "avatar":
+			value = "Foto profil akun Anda"
+		case "role":
+			value = c.Role
+		case "positions":
+			var names []string
+			for _, p := range c.Positions {
+				names = append(names, p.Name)
+			}
+			value = strings.Join(names, ", ")
+		case "groups":
+			value = strings.Join(c.Groups, ", ")
+		case models.ClaimAdviseeAPI:
+			value = "Nama, email, dan NIM mahasiswa bimbingan Anda"
+		default:
+			value = c.Profile[key]
+		}
+		items = append(items, ConsentItem{Label: models.ClaimLabel(key), Value: value})
+	}
+	return items
+}
+
+// renderConsent menampilkan layar persetujuan sebelum token pertama kali dikirim ke aplikasi.
+func (rc *RedirectController) renderConsent(w http.ResponseWriter, r *http.Request, app models.Application, claims *Claims, changed bool) {
+	rc.views.RenderPage(w, r, "consent", map[string]interface{}{
+		"App":         app,
+		"Items":       consentItems(claims),
+		"Fingerprint": claimFingerprint(claims),
+		"Changed":     changed,
+		"Path":        claims.Path,
+		"Force":       r.URL.Query().Get("force"),
+	})
+}
+
+// GrantConsent menyimpan persetujuan user lalu melanjutkan proses redirect ke aplikasi.
+func (rc *RedirectController) GrantConsent(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	appSlug := r.FormValue("app")
+	app, err := models.FindApplicationBySlug(rc.env.DB, appSlug)
+	if err != nil {
+		rc.RenderError(w, r, http.StatusNotFound, "Aplikasi Tidak Ditemukan.")
+		return
+	}
+
+	access, err := models.CanAccessApp(rc.env.DB, user, app.ID, models.NewAccessContext(middleware.ClientIP(r)))
+	if err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if !access.Decision.Allowed {
+		rc.RenderError(w, r, http.StatusForbidden, "Anda tidak memiliki akses ke aplikasi ini.")
+		return
+	}
+
+	claims, err := rc.buildClaims(user, app, "")
+	if err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	query := url.Values{"app": {app.Slug}}
+	if path := r.FormValue("path"); path != "" {
+		query.Set("path", path)
+	}
+	if r.FormValue("force") == "1" {
+		query.Set("force", "1")
+	}
+	redirectURL := "/redirect?" + query.Encode()
+
+	fingerprint := claimFingerprint(claims)
+	// Data user berubah sejak layar persetujuan ditampilkan; tampilkan ulang agar yang disetujui sesuai yang dikirim
+	if r.FormValue("claims") != fingerprint {
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	if err := models.GrantAppConsent(rc.env.DB, user.ID, app.ID, fingerprint); err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
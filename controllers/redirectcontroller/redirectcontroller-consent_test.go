@@ -0,0 +1,64 @@
+package redirectcontroller
+
+import "testing"
+
+func TestClaimFingerprint(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims Claims
+		want   string
+	}{
+		{"klaim dasar", Claims{}, "avatar,email,name,role"},
+		{"profil mahasiswa terurut", Claims{Profile: map[string]string{"student_id": "1", "cohort_year": "2023"}},
+			"avatar,cohort_year,email,name,role,student_id"},
+		{"jabatan dan grup hanya jika ada", Claims{Positions: []PositionClaim{{Name: "Kaprodi"}}, Groups: []string{"dosen-ti"}},
+			"avatar,email,groups,name,positions,role"},
+		{"jabatan kosong tidak dihitung", Claims{Positions: []PositionClaim{}, Groups: []string{}},
+			"avatar,email,name,role"},
+		{"path deep link tidak dihitung", Claims{Path: "/krs/1"}, "avatar,email,name,role"},
+		{"token API mahasiswa bimbingan", Claims{AdviseeAPI: true}, "advisee_api,avatar,email,name,role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := claimFingerprint(&tt.claims); got != tt.want {
+				t.Errorf("claimFingerprint = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimFingerprintIgnoresValues(t *testing.T) {
+	// Persetujuan tidak perlu diminta ulang hanya karena nilai klaim berubah
+	a := &Claims{Name: "Budi", Profile: map[string]string{"academic_status": "aktif"}}
+	b := &Claims{Name: "Budi Santoso", Profile: map[string]string{"academic_status": "cuti"}}
+	if claimFingerprint(a) != claimFingerprint(b) {
+		t.Errorf("fingerprint berbeda: %q vs %q", claimFingerprint(a), claimFingerprint(b))
+	}
+
+	c := &Claims{Profile: map[string]string{"academic_status": "aktif", "advisor_id": "3"}}
+	if claimFingerprint(a) == claimFingerprint(c) {
+		t.Errorf("klaim baru tidak mengubah fingerprint: %q", claimFingerprint(c))
+	}
+}
+
+func TestConsentItems(t *testing.T) {
+	claims := &Claims{Name: "Budi", Email: "budi@pnc.ac.id", Role: "dosen", AdviseeAPI: true,
+		Positions: []PositionClaim{{Name: "Kaprodi"}, {Name: "Sekjur"}}}
+
+	items := consentItems(claims)
+	if len(items) != len(claimKeys(claims)) {
+		t.Fatalf("jumlah item = %d; want %d", len(items), len(claimKeys(claims)))
+	}
+
+	values := map[string]string{}
+	for _, item := range items {
+		values[item.Label] = item.Value
+	}
+	if values["Jabatan"] != "Kaprodi, Sekjur" || values["Nama lengkap"] != "Budi" {
+		t.Errorf("consentItems = %+v", items)
+	}
+	if values["Daftar mahasiswa bimbingan"] == "" {
+		t.Errorf("akses API mahasiswa bimbingan tidak ditampilkan: %+v", items)
+	}
+}
@@ -1,12 +1,14 @@
 package redirectcontroller
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"sso-portal-v5/config"
+	"sso-portal-v5/middleware"
 	"sso-portal-v5/models"
 	"sso-portal-v5/views"
 	"time"
@@ -30,14 +32,27 @@ type Claims struct {
 	Avatar  string            `json:"avatar"`
 	Role    string            `json:"role"`
 	Profile map[string]string `json:"profile"`
+	Positions []PositionClaim `json:"positions,omitempty"`
+	Groups    []string        `json:"groups,omitempty"` // Slug grup pengguna
+	Path      string          `json:"path,omitempty"`   // Deep link: path tujuan di aplikasi (lihat ResolveDeepLink)
+	// AdviseeAPI menandakan token API mahasiswa bimbingan ikut dikirim; bagian dari persetujuan, bukan isi token.
+	AdviseeAPI bool `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// PositionClaim adalah jabatan dosen yang sedang berlaku, beserta unitnya jika ada.
+type PositionClaim struct {
+	PositionID     int    `json:"position_id"`
+	Name           string `json:"name"`
+	MajorID        int64  `json:"major_id,omitempty"`
+	StudyProgramID int64  `json:"study_program_id,omitempty"`
+	EndDate        string `json:"end_date,omitempty"`
+}
+
 // RedirectToApp membuat JWT dan mengarahkan pengguna ke aplikasi tujuan.
 func (rc *RedirectController) RedirectToApp(w http.ResponseWriter, r *http.Request) {
 
 	user := r.Context().Value("UserLogin").(*models.FullUser)
-	role := user.Roles[0].Name
 
 	appSlug := r.URL.Query().Get("app")
 	if appSlug == "" {
@@ -51,13 +66,192 @@ func (rc *RedirectController) RedirectToApp(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Cek hak akses dengan aturan yang sama seperti daftar aplikasi di dashboard
+	access, err := models.CanAccessApp(rc.env.DB, user, app.ID, models.NewAccessContext(middleware.ClientIP(r)))
+	if err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if !access.Decision.Allowed {
+		rc.RenderError(w, r, http.StatusForbidden, "Anda tidak memiliki akses ke aplikasi ini.")
+		return
+	}
+	if !access.Availability.Open {
+		rc.RenderError(w, r, http.StatusForbidden, app.Name+" sedang ditutup. "+access.Availability.Message())
+		return
+	}
+
+	// Aplikasi dalam pemeliharaan tidak dapat dibuka; aplikasi yang terdeteksi down masih dapat dibuka paksa
+	if access.MaintenanceMode || (access.HealthStatus == "down" && r.URL.Query().Get("force") != "1") {
+		rc.renderMaintenance(w, r, access.Application)
+		return
+	}
+
+	// Deep link ke halaman tertentu, hanya untuk path dengan awalan yang diizinkan aplikasi
+	deepLink := r.URL.Query().Get("path")
+	if deepLink == "" {
+		deepLink = r.URL.Query().Get("target")
+	}
+	if deepLink != "" {
+		deepLink, err = models.ResolveDeepLink(rc.env.DB, app.ID, deepLink)
+		if errors.Is(err, models.ErrDeepLinkNotAllowed) {
+			rc.RenderError(w, r, http.StatusBadRequest, "Tautan tujuan tidak diizinkan untuk aplikasi ini.")
+			return
+		}
+		if err != nil {
+			rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+	}
+
+	claims, err := rc.buildClaims(user, app, deepLink)
+	if err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	// Persetujuan diminta saat aplikasi pertama kali dibuka dan setiap kali daftar data yang dikirim berubah
+	consent, err := models.GetAppConsent(rc.env.DB, user.ID, app.ID)
+	if err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if consent == nil || consent.Claims != claimFingerprint(claims) {
+		rc.renderConsent(w, r, app, claims, consent != nil)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+
+	tokenString, err := token.SignedString(config.PrivateKey)
+	if err != nil {
+		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	// Token API hanya untuk aplikasi yang diizinkan memanggil API dan selalu lewat form POST, tidak pernah di URL
+	var apiToken string
+	if claims.AdviseeAPI {
+		apiToken, err = rc.signAPIToken(user, app.Slug)
+		if err != nil {
+			rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+			log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+			return
+		}
+	}
+	go models.ClearNotification(rc.env.DB, user.ID, app.ID)
+
+	if err := models.RecordAppLaunch(rc.env.DB, user.ID, app.ID, user.Roles[0].RoleID); err != nil {
+		log.Printf("WARNING: Gagal mencatat pembukaan aplikasi %s oleh user %d: %v", app.Slug, user.ID, err)
+	}
+
+	if app.TokenDelivery == models.TokenDeliveryFormPost {
+		rc.renderFormPost(w, r, app, tokenString, apiToken, deepLink)
+		return
+	}
+
+	finalURL := fmt.Sprintf("%s?token=%s", app.TargetURL, url.QueryEscape(tokenString))
+	if deepLink != "" {
+		finalURL += "&return_to=" + url.QueryEscape(deepLink)
+	}
+
+	http.Redirect(w, r, finalURL, http.StatusTemporaryRedirect)
+}
+
+// renderFormPost menampilkan form yang otomatis mengirim token ke endpoint login aplikasi dengan POST
+// (setara response_mode=form_post OIDC). Halaman tidak boleh di-cache karena berisi token.
+func (rc *RedirectController) renderFormPost(w http.ResponseWriter, r *http.Request, app models.Application, token, apiToken, returnTo string) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	rc.views.RenderPage(w, r, "form-post", map[string]interface{}{
+		"App":      app,
+		"Action":   app.TokenEndpoint(),
+		"Token":    token,
+		"APIToken": apiToken,
+		"ReturnTo": returnTo,
+	})
+}
+
+// APIClaims adalah isi token akses API portal. AuthorizedParty mencatat aplikasi yang menerima token.
+type APIClaims struct {
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// apiTokenTTL adalah masa berlaku token akses API, lebih panjang dari token login yang hanya dipakai sekali.
+const apiTokenTTL = time.Hour
+
+// signAPIToken menerbitkan token akses API terpisah dari token login, dengan audience khusus API
+// sehingga token login aplikasi mana pun tidak dapat dipakai memanggil API.
+func (rc *RedirectController) signAPIToken(user *models.FullUser, appSlug string) (string, error) {
+	now := time.Now()
+	claims := &APIClaims{
+		AuthorizedParty: appSlug,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(apiTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   fmt.Sprintf("%d", user.ID),
+			Issuer:    config.Issuer,
+			Audience:  jwt.ClaimStrings{config.APIAudience},
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(config.PrivateKey)
+}
+
+// buildClaims menyusun klaim token untuk user. Isi klaim ini pula yang ditampilkan pada layar persetujuan.
+func (rc *RedirectController) buildClaims(user *models.FullUser, app models.Application, deepLink string) (*Claims, error) {
 	profileData := make(map[string]string)
 	if user.Student != nil && user.Student.ID != 0 {
 		profileData["student_id"] = fmt.Sprintf("%d", user.Student.ID)
+		profileData["academic_status"] = user.Student.AcademicStatus
+		if user.Student.StudyProgramID.Valid {
+			profileData["study_program_id"] = fmt.Sprintf("%d", user.Student.StudyProgramID.Int64)
+		}
+		if user.Student.MajorID.Valid {
+			profileData["major_id"] = fmt.Sprintf("%d", user.Student.MajorID.Int64)
+		}
+		if user.Student.CohortYear.Valid {
+			profileData["cohort_year"] = fmt.Sprintf("%d", user.Student.CohortYear.Int64)
+		}
+		// Dosen wali dikirim sebagai user id (sama dengan subject token dosen tersebut)
+		if user.Student.AdvisorUserID.Valid {
+			profileData["advisor_id"] = fmt.Sprintf("%d", user.Student.AdvisorUserID.Int64)
+		}
 	}
 
 	if user.Lecturer != nil && user.Lecturer.ID != 0 {
 		profileData["lecturer_id"] = fmt.Sprintf("%d", user.Lecturer.ID)
+
+		adviseeCount, err := models.CountAdvisees(rc.env.DB, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		profileData["advisee_count"] = fmt.Sprintf("%d", adviseeCount)
+	}
+
+	// user.Positions hanya berisi jabatan yang sedang berlaku
+	var positionClaims []PositionClaim
+	for _, p := range user.Positions {
+		claim := PositionClaim{PositionID: p.PositionID, Name: p.PositionName, MajorID: p.MajorID.Int64, StudyProgramID: p.StudyProgramID.Int64}
+		if p.EndDate != nil {
+			claim.EndDate = *p.EndDate
+		}
+		positionClaims = append(positionClaims, claim)
+	}
+
+	groups, err := models.GetUserGroups(rc.env.DB, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var groupClaims []string
+	for _, g := range groups {
+		groupClaims = append(groupClaims, g.Slug)
 	}
 
 	expirationTime := time.Now().Add(2 * time.Minute)
@@ -65,29 +259,35 @@ func (rc *RedirectController) RedirectToApp(w http.ResponseWriter, r *http.Reque
 		Name:    user.Name,
 		Email:   user.Email,
 		Avatar:  fmt.Sprintf("%s/avatar/%d", os.Getenv("APP_BASE_URL"), user.ID),
-		Role:    role,
+		Role:    user.Roles[0].Name,
 		Profile: profileData,
+		Positions: positionClaims,
+		Groups:    groupClaims,
+		Path:      deepLink,
+		// API mahasiswa bimbingan hanya berguna bagi dosen
+		AdviseeAPI: app.AdviseeAPI && app.TokenDelivery == models.TokenDeliveryFormPost && user.Lecturer != nil && user.Lecturer.ID != 0,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			Subject:   fmt.Sprintf("%d", user.ID),
 			Issuer:   config.Issuer,
-			Audience:  jwt.ClaimStrings{appSlug},
+			Audience:  jwt.ClaimStrings{app.Slug},
 		},
 	}
+	return claims, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-
-	tokenString, err := token.SignedString(config.PrivateKey)
-	if err != nil {
-		rc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
-		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
-		return
-	}
-	finalURL := fmt.Sprintf("%s?token=%s", app.TargetURL, url.QueryEscape(tokenString))
+// renderMaintenance menampilkan halaman pemeliharaan/gangguan sebagai pengganti redirect ke aplikasi.
+func (rc *RedirectController) renderMaintenance(w http.ResponseWriter, r *http.Request, app models.Application) {
+	w.WriteHeader(http.StatusServiceUnavailable)
 
-	go models.ClearNotification(rc.env.DB, user.ID, app.ID)
+	query := r.URL.Query()
+	query.Set("force", "1")
 
-	http.Redirect(w, r, finalURL, http.StatusTemporaryRedirect)
+	rc.views.RenderPage(w, r, "maintenance", map[string]interface{}{
+		"App":         app,
+		"Maintenance": app.MaintenanceMode,
+		"ForceURL":    r.URL.Path + "?" + query.Encode(),
+	})
 }
 
 func (ac *RedirectController) RenderError(w http.ResponseWriter, r *http.Request, code int, message string) {
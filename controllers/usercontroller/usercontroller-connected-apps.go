@@ -0,0 +1,64 @@
+package usercontroller
+
+import (
+	"log"
+	"net/http"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ShowConnectedApps menampilkan aplikasi yang sudah disetujui user beserta data yang dikirim ke aplikasi tersebut
+func (uc *UserController) ShowConnectedApps(w http.ResponseWriter, r *http.Request) {
+	session, _ := uc.env.Store.Get(r, uc.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	consents, err := models.GetUserConsents(uc.env.DB, user.ID)
+	if err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	uc.views.RenderPage(w, r, "connected-apps", map[string]interface{}{
+		"Consents": consents,
+		"Flash":    flashMsg,
+	})
+}
+
+// RevokeConnectedApp mencabut persetujuan user untuk sebuah aplikasi dan memberi tahu aplikasi tersebut.
+// Saat aplikasi dibuka lagi, layar persetujuan akan ditampilkan kembali.
+func (uc *UserController) RevokeConnectedApp(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	app, _, _, err := models.FindApplicationByID(uc.env.DB, mux.Vars(r)["id"])
+	if err != nil {
+		uc.RenderError(w, r, http.StatusNotFound, "Aplikasi Tidak Ditemukan.")
+		return
+	}
+
+	revoked, err := models.RevokeAppConsent(uc.env.DB, user.ID, app.ID)
+	if err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if revoked {
+		services.NotifyConsentRevoked(uc.env, app, user.ID)
+	}
+
+	session, _ := uc.env.Store.Get(r, uc.env.SessionName)
+	session.AddFlash("Akses " + app.Name + " ke data Anda telah dicabut.")
+	session.Save(r, w)
+
+	http.Redirect(w, r, "/profile/connected-apps", http.StatusFound)
+}
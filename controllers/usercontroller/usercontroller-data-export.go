@@ -0,0 +1,127 @@
+package usercontroller
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// dataExportCooldown adalah jeda minimal antar permintaan export data pribadi.
+const dataExportCooldown = time.Hour
+
+// ShowDataExport menampilkan halaman unduh data pribadi beserta status permintaan terakhir
+func (uc *UserController) ShowDataExport(w http.ResponseWriter, r *http.Request) {
+	session, _ := uc.env.Store.Get(r, uc.env.SessionName)
+	flashes := session.Flashes()
+	session.Save(r, w)
+
+	var flashMsg string
+	if len(flashes) > 0 {
+		flashMsg = flashes[0].(string)
+	}
+
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	export, err := models.FindLatestDataExport(uc.env.DB, user.ID)
+	if err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	uc.views.RenderPage(w, r, "data-export", map[string]interface{}{
+		"Export": export,
+		"TTL":    int(services.DataExportTTL.Hours()),
+		"Flash":  flashMsg,
+	})
+}
+
+// RequestDataExport membuat permintaan export baru; arsip disusun di background
+func (uc *UserController) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+	session, _ := uc.env.Store.Get(r, uc.env.SessionName)
+
+	latest, err := models.FindLatestDataExport(uc.env.DB, user.ID)
+	if err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if latest != nil && latest.Status == models.DataExportPending {
+		session.AddFlash("Permintaan sebelumnya masih diproses.")
+		session.Save(r, w)
+		http.Redirect(w, r, "/profile/data-export", http.StatusFound)
+		return
+	}
+	if latest != nil && time.Since(latest.RequestedAt) < dataExportCooldown {
+		session.AddFlash(fmt.Sprintf("Export hanya dapat diminta sekali setiap %d menit.", int(dataExportCooldown.Minutes())))
+		session.Save(r, w)
+		http.Redirect(w, r, "/profile/data-export", http.StatusFound)
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	token := hex.EncodeToString(b)
+
+	id, err := models.CreateDataExport(uc.env.DB, user.ID, token)
+	if err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+
+	services.GenerateDataExport(uc.env, int(id), user.ID, token)
+
+	session.AddFlash("Permintaan diterima. Anda akan diberi tahu saat arsip siap diunduh.")
+	session.Save(r, w)
+	http.Redirect(w, r, "/profile/data-export", http.StatusFound)
+}
+
+// DownloadDataExport mengirim arsip export milik user selama tautannya belum kedaluwarsa
+func (uc *UserController) DownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("UserLogin").(*models.FullUser)
+
+	export, err := models.FindDataExportByToken(uc.env.DB, user.ID, mux.Vars(r)["token"])
+	if err == sql.ErrNoRows {
+		uc.RenderError(w, r, http.StatusNotFound, "Arsip Tidak Ditemukan.")
+		return
+	}
+	if err != nil {
+		uc.RenderError(w, r, http.StatusInternalServerError, "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.")
+		log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+		return
+	}
+	if !export.Downloadable() || !export.FilePath.Valid {
+		uc.RenderError(w, r, http.StatusGone, "Tautan unduhan sudah tidak berlaku. Silakan minta export baru.")
+		return
+	}
+
+	f, err := os.Open(export.FilePath.String)
+	if err != nil {
+		uc.RenderError(w, r, http.StatusGone, "Tautan unduhan sudah tidak berlaku. Silakan minta export baru.")
+		log.Printf("WARNING: File export %s tidak dapat dibuka: %v", export.FilePath.String, err)
+		return
+	}
+	defer f.Close()
+
+	fileName := fmt.Sprintf("data-saya-%s.zip", export.CompletedAt.Time.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	w.Header().Set("Cache-Control", "no-store")
+
+	http.ServeContent(w, r, fileName, export.CompletedAt.Time, f)
+}
@@ -23,6 +23,181 @@ SET time_zone = "+00:00";
 
 -- --------------------------------------------------------
 
+--
+-- Table structure for table `access_requests`
+--
+
+CREATE TABLE `access_requests` (
+  `id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `user_id` int NOT NULL,
+  `justification` text NOT NULL,
+  `status` enum('pending','approved','denied') NOT NULL DEFAULT 'pending',
+  `grant_expires_at` date DEFAULT NULL,
+  `review_note` varchar(255) DEFAULT NULL,
+  `reviewed_by` int DEFAULT NULL,
+  `reviewed_at` datetime DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `admin_scopes`
+--
+
+CREATE TABLE `admin_scopes` (
+  `id` int NOT NULL,
+  `user_id` int NOT NULL,
+  `major_id` int DEFAULT NULL,
+  `study_program_id` int DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_approvers`
+--
+
+CREATE TABLE `application_approvers` (
+  `application_id` int NOT NULL,
+  `user_id` int NOT NULL,
+  `added_by` int DEFAULT NULL,
+  `added_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_availability_windows`
+--
+
+CREATE TABLE `application_availability_windows` (
+  `id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `role_id` int DEFAULT NULL COMMENT 'NULL = jadwal umum, diisi = jadwal pengganti untuk role tersebut',
+  `label` varchar(100) NOT NULL,
+  `opens_at` datetime DEFAULT NULL,
+  `closes_at` datetime DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_deep_link_prefixes`
+--
+
+CREATE TABLE `application_deep_link_prefixes` (
+  `id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `prefix` varchar(255) NOT NULL COMMENT 'Awalan path yang boleh dituju melalui /redirect, mis. /krs/',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_group_access`
+--
+
+CREATE TABLE `application_group_access` (
+  `application_id` int NOT NULL,
+  `group_id` int NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_health_checks`
+--
+
+CREATE TABLE `application_health_checks` (
+  `id` bigint NOT NULL,
+  `application_id` int NOT NULL,
+  `status` enum('up','down') NOT NULL,
+  `http_status` int DEFAULT NULL,
+  `response_ms` int DEFAULT NULL,
+  `error` varchar(255) DEFAULT NULL,
+  `checked_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_launches`
+--
+
+CREATE TABLE `application_launches` (
+  `id` bigint NOT NULL,
+  `user_id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `role_id` int DEFAULT NULL,
+  `launched_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_policies`
+--
+
+CREATE TABLE `application_policies` (
+  `id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `effect` enum('allow','deny') NOT NULL,
+  `priority` int NOT NULL DEFAULT '100',
+  `is_active` tinyint(1) NOT NULL DEFAULT '1',
+  `role_id` int DEFAULT NULL,
+  `position_id` int DEFAULT NULL,
+  `major_id` int DEFAULT NULL,
+  `study_program_id` int DEFAULT NULL,
+  `academic_status` enum('aktif','cuti','lulus','do') DEFAULT NULL,
+  `group_id` int DEFAULT NULL,
+  `days_of_week` varchar(20) DEFAULT NULL COMMENT 'ISO weekday 1 (Senin) - 7 (Minggu), dipisah koma',
+  `start_time` time DEFAULT NULL,
+  `end_time` time DEFAULT NULL,
+  `ip_ranges` varchar(500) DEFAULT NULL COMMENT 'CIDR atau IP, dipisah koma',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_student_access`
+--
+
+CREATE TABLE `application_student_access` (
+  `id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `major_id` int DEFAULT NULL,
+  `study_program_id` int DEFAULT NULL,
+  `cohort_year` smallint DEFAULT NULL,
+  `academic_status` enum('aktif','cuti','lulus','do') DEFAULT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `application_user_overrides`
+--
+
+CREATE TABLE `application_user_overrides` (
+  `id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `user_id` int NOT NULL,
+  `effect` enum('allow','deny') NOT NULL,
+  `reason` varchar(255) DEFAULT NULL,
+  `expires_at` date DEFAULT NULL,
+  `created_by` int DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
 --
 -- Table structure for table `applications`
 --
@@ -34,7 +209,16 @@ CREATE TABLE `applications` (
   `slug` varchar(255) NOT NULL,
   `target_url` varchar(255) NOT NULL,
   `icon_url` varchar(255) DEFAULT NULL,
-  `category_id` int NOT NULL
+  `category_id` int NOT NULL,
+  `health_url` varchar(255) DEFAULT NULL COMMENT 'URL yang diperiksa health checker, kosong = target_url',
+  `health_status` enum('unknown','up','down') NOT NULL DEFAULT 'unknown',
+  `health_checked_at` datetime DEFAULT NULL,
+  `maintenance_mode` tinyint(1) NOT NULL DEFAULT '0',
+  `maintenance_message` varchar(255) DEFAULT NULL,
+  `token_delivery` enum('query','form_post') NOT NULL DEFAULT 'query' COMMENT 'Cara token dikirim ke aplikasi: query string atau form POST otomatis',
+  `token_post_url` varchar(255) DEFAULT NULL COMMENT 'Endpoint login yang menerima POST token, kosong = target_url',
+  `revocation_url` varchar(255) DEFAULT NULL COMMENT 'Endpoint yang diberi tahu saat user mencabut persetujuan',
+  `advisee_api` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'Aplikasi boleh memanggil API mahasiswa bimbingan (token API hanya dikirim lewat form POST)'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 -- --------------------------------------------------------
@@ -58,8 +242,11 @@ CREATE TABLE `application_notifications` (
 --
 
 CREATE TABLE `application_position_access` (
+  `id` int NOT NULL,
   `application_id` int NOT NULL,
-  `position_id` int NOT NULL
+  `position_id` int NOT NULL,
+  `major_id` int DEFAULT NULL,
+  `study_program_id` int DEFAULT NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 -- --------------------------------------------------------
@@ -95,7 +282,8 @@ CREATE TABLE `lecturers` (
   `id` int NOT NULL,
   `user_id` int NOT NULL,
   `nip` varchar(30) DEFAULT NULL,
-  `nuptk` varchar(30) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci DEFAULT NULL
+  `nuptk` varchar(30) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci DEFAULT NULL,
+  `study_program_id` int DEFAULT NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 -- --------------------------------------------------------
@@ -112,6 +300,8 @@ CREATE TABLE `lecturer_positions` (
   `study_program_id` int DEFAULT NULL,
   `start_date` date DEFAULT NULL,
   `end_date` date DEFAULT NULL,
+  `expiry_notified_at` timestamp NULL DEFAULT NULL,
+  `ended_at` timestamp NULL DEFAULT NULL,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `deleted_at` timestamp NULL DEFAULT NULL
@@ -147,6 +337,60 @@ CREATE TABLE `positions` (
 
 -- --------------------------------------------------------
 
+--
+-- Table structure for table `provisioning_rules`
+--
+
+CREATE TABLE `provisioning_rules` (
+  `id` int NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `domain` varchar(100) NOT NULL,
+  `email_pattern` varchar(255) NOT NULL,
+  `role_id` int NOT NULL,
+  `nim_pattern` varchar(255) DEFAULT NULL,
+  `requires_verification` tinyint(1) NOT NULL DEFAULT '0',
+  `is_active` tinyint(1) NOT NULL DEFAULT '1',
+  `priority` int NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `registration_requests`
+--
+
+CREATE TABLE `registration_requests` (
+  `id` int NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `email` varchar(255) NOT NULL,
+  `role_id` int NOT NULL,
+  `nim` varchar(20) DEFAULT NULL,
+  `nip` varchar(30) DEFAULT NULL,
+  `nuptk` varchar(30) DEFAULT NULL,
+  `study_program_id` int DEFAULT NULL,
+  `status` enum('pending','approved','rejected') NOT NULL DEFAULT 'pending',
+  `review_note` varchar(255) DEFAULT NULL,
+  `reviewed_by` int DEFAULT NULL,
+  `reviewed_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `role_permissions`
+--
+
+CREATE TABLE `role_permissions` (
+  `role_id` int NOT NULL,
+  `permission` varchar(64) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
 --
 -- Table structure for table `roles`
 --
@@ -162,6 +406,19 @@ CREATE TABLE `roles` (
 
 -- --------------------------------------------------------
 
+--
+-- Table structure for table `student_advisors`
+--
+
+CREATE TABLE `student_advisors` (
+  `student_user_id` int NOT NULL,
+  `advisor_user_id` int NOT NULL,
+  `assigned_by` int DEFAULT NULL,
+  `assigned_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
 --
 -- Table structure for table `students`
 --
@@ -169,7 +426,10 @@ CREATE TABLE `roles` (
 CREATE TABLE `students` (
   `id` int NOT NULL,
   `user_id` int NOT NULL,
-  `nim` varchar(20) NOT NULL
+  `nim` varchar(20) NOT NULL,
+  `study_program_id` int DEFAULT NULL,
+  `cohort_year` smallint DEFAULT NULL,
+  `academic_status` enum('aktif','cuti','lulus','do') NOT NULL DEFAULT 'aktif'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 -- --------------------------------------------------------
@@ -189,6 +449,100 @@ CREATE TABLE `study_programs` (
 
 -- --------------------------------------------------------
 
+--
+-- Table structure for table `user_app_consents`
+--
+
+CREATE TABLE `user_app_consents` (
+  `user_id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `claims` varchar(500) NOT NULL COMMENT 'Daftar klaim yang disetujui, dipisah koma dan terurut',
+  `granted_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `user_data_exports`
+--
+
+CREATE TABLE `user_data_exports` (
+  `id` int NOT NULL,
+  `user_id` int NOT NULL,
+  `token` char(64) NOT NULL COMMENT 'Token acak untuk tautan unduhan',
+  `status` enum('pending','ready','failed') NOT NULL DEFAULT 'pending',
+  `file_path` varchar(255) DEFAULT NULL,
+  `file_size` bigint DEFAULT NULL,
+  `requested_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `completed_at` timestamp NULL DEFAULT NULL,
+  `expires_at` timestamp NULL DEFAULT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `user_favorite_apps`
+--
+
+CREATE TABLE `user_favorite_apps` (
+  `user_id` int NOT NULL,
+  `application_id` int NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `user_group_members`
+--
+
+CREATE TABLE `user_group_members` (
+  `group_id` int NOT NULL,
+  `user_id` int NOT NULL,
+  `added_by` int DEFAULT NULL,
+  `added_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `user_groups`
+--
+
+CREATE TABLE `user_groups` (
+  `id` int NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `slug` varchar(100) NOT NULL,
+  `description` varchar(255) DEFAULT NULL,
+  `membership_type` enum('static','dynamic') NOT NULL DEFAULT 'static',
+  `rule_target` enum('mahasiswa','dosen') DEFAULT NULL,
+  `rule_major_id` int DEFAULT NULL,
+  `rule_study_program_id` int DEFAULT NULL,
+  `rule_cohort_year` smallint DEFAULT NULL,
+  `rule_academic_status` enum('aktif','cuti','lulus','do') DEFAULT NULL,
+  `rule_has_position` tinyint(1) NOT NULL DEFAULT '0',
+  `rule_position_id` int DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `user_logins`
+--
+
+CREATE TABLE `user_logins` (
+  `id` bigint NOT NULL,
+  `user_id` int NOT NULL,
+  `role_id` int DEFAULT NULL,
+  `ip_address` varchar(45) DEFAULT NULL,
+  `user_agent` varchar(255) DEFAULT NULL,
+  `logged_in_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- --------------------------------------------------------
+
 --
 -- Table structure for table `users`
 --
@@ -199,7 +553,7 @@ CREATE TABLE `users` (
   `email` varchar(255) NOT NULL,
   `avatar` varchar(255) DEFAULT NULL,
   `google_avatar` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci DEFAULT NULL,
-  `status` enum('aktif','nonaktif') CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'aktif',
+  `status` enum('aktif','nonaktif','pending') CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'aktif',
   `address` text,
   `phone_number` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci DEFAULT NULL,
   `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
@@ -238,6 +592,102 @@ CREATE TABLE `user_roles` (
 -- Indexes for dumped tables
 --
 
+--
+-- Indexes for table `access_requests`
+--
+ALTER TABLE `access_requests`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `application_id` (`application_id`,`status`),
+  ADD KEY `user_id` (`user_id`),
+  ADD KEY `reviewed_by` (`reviewed_by`);
+
+--
+-- Indexes for table `admin_scopes`
+--
+ALTER TABLE `admin_scopes`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `user_id` (`user_id`),
+  ADD KEY `major_id` (`major_id`),
+  ADD KEY `study_program_id` (`study_program_id`);
+
+--
+-- Indexes for table `application_approvers`
+--
+ALTER TABLE `application_approvers`
+  ADD PRIMARY KEY (`application_id`,`user_id`),
+  ADD KEY `user_id` (`user_id`),
+  ADD KEY `added_by` (`added_by`);
+
+--
+-- Indexes for table `application_availability_windows`
+--
+ALTER TABLE `application_availability_windows`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `application_id` (`application_id`,`role_id`),
+  ADD KEY `role_id` (`role_id`);
+
+--
+-- Indexes for table `application_deep_link_prefixes`
+--
+ALTER TABLE `application_deep_link_prefixes`
+  ADD PRIMARY KEY (`id`),
+  ADD UNIQUE KEY `application_prefix` (`application_id`,`prefix`);
+
+--
+-- Indexes for table `application_group_access`
+--
+ALTER TABLE `application_group_access`
+  ADD PRIMARY KEY (`application_id`,`group_id`),
+  ADD KEY `group_id` (`group_id`);
+
+--
+-- Indexes for table `application_health_checks`
+--
+ALTER TABLE `application_health_checks`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `application_id` (`application_id`,`checked_at`),
+  ADD KEY `checked_at` (`checked_at`);
+
+--
+-- Indexes for table `application_launches`
+--
+ALTER TABLE `application_launches`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `launched_at` (`launched_at`),
+  ADD KEY `application_id` (`application_id`,`launched_at`),
+  ADD KEY `user_id` (`user_id`),
+  ADD KEY `role_id` (`role_id`);
+
+--
+-- Indexes for table `application_policies`
+--
+ALTER TABLE `application_policies`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `application_id` (`application_id`,`priority`),
+  ADD KEY `role_id` (`role_id`),
+  ADD KEY `position_id` (`position_id`),
+  ADD KEY `major_id` (`major_id`),
+  ADD KEY `study_program_id` (`study_program_id`),
+  ADD KEY `group_id` (`group_id`);
+
+--
+-- Indexes for table `application_student_access`
+--
+ALTER TABLE `application_student_access`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `application_id` (`application_id`),
+  ADD KEY `major_id` (`major_id`),
+  ADD KEY `study_program_id` (`study_program_id`);
+
+--
+-- Indexes for table `application_user_overrides`
+--
+ALTER TABLE `application_user_overrides`
+  ADD PRIMARY KEY (`id`),
+  ADD UNIQUE KEY `application_user` (`application_id`,`user_id`),
+  ADD KEY `user_id` (`user_id`),
+  ADD KEY `created_by` (`created_by`);
+
 --
 -- Indexes for table `applications`
 --
@@ -258,9 +708,11 @@ ALTER TABLE `application_notifications`
 -- Indexes for table `application_position_access`
 --
 ALTER TABLE `application_position_access`
-  ADD PRIMARY KEY (`application_id`,`position_id`),
+  ADD PRIMARY KEY (`id`),
   ADD KEY `position_id` (`position_id`) USING BTREE,
-  ADD KEY `application_id` (`application_id`,`position_id`);
+  ADD KEY `application_id` (`application_id`,`position_id`),
+  ADD KEY `major_id` (`major_id`),
+  ADD KEY `study_program_id` (`study_program_id`);
 
 --
 -- Indexes for table `application_role_access`
@@ -284,7 +736,8 @@ ALTER TABLE `lecturers`
   ADD PRIMARY KEY (`id`),
   ADD UNIQUE KEY `user_id` (`user_id`),
   ADD UNIQUE KEY `nip` (`nip`),
-  ADD UNIQUE KEY `nidn` (`nuptk`);
+  ADD UNIQUE KEY `nidn` (`nuptk`),
+  ADD KEY `study_program_id` (`study_program_id`);
 
 --
 -- Indexes for table `lecturer_positions`
@@ -293,7 +746,8 @@ ALTER TABLE `lecturer_positions`
   ADD PRIMARY KEY (`id`),
   ADD KEY `lecturer_id` (`lecturer_id`),
   ADD KEY `position_id` (`position_id`),
-  ADD KEY `major_id` (`major_id`,`study_program_id`);
+  ADD KEY `major_id` (`major_id`,`study_program_id`),
+  ADD KEY `end_date` (`end_date`);
 
 --
 -- Indexes for table `majors`
@@ -307,6 +761,28 @@ ALTER TABLE `majors`
 ALTER TABLE `positions`
   ADD PRIMARY KEY (`id`);
 
+--
+-- Indexes for table `provisioning_rules`
+--
+ALTER TABLE `provisioning_rules`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `role_id` (`role_id`);
+
+--
+-- Indexes for table `registration_requests`
+--
+ALTER TABLE `registration_requests`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `email` (`email`,`status`),
+  ADD KEY `role_id` (`role_id`),
+  ADD KEY `study_program_id` (`study_program_id`);
+
+--
+-- Indexes for table `role_permissions`
+--
+ALTER TABLE `role_permissions`
+  ADD PRIMARY KEY (`role_id`,`permission`);
+
 --
 -- Indexes for table `roles`
 --
@@ -314,13 +790,23 @@ ALTER TABLE `roles`
   ADD PRIMARY KEY (`id`),
   ADD UNIQUE KEY `name` (`role_name`);
 
+--
+-- Indexes for table `student_advisors`
+--
+ALTER TABLE `student_advisors`
+  ADD PRIMARY KEY (`student_user_id`),
+  ADD KEY `advisor_user_id` (`advisor_user_id`),
+  ADD KEY `assigned_by` (`assigned_by`);
+
 --
 -- Indexes for table `students`
 --
 ALTER TABLE `students`
   ADD PRIMARY KEY (`id`),
   ADD UNIQUE KEY `user_id` (`user_id`),
-  ADD UNIQUE KEY `nim` (`nim`);
+  ADD UNIQUE KEY `nim` (`nim`),
+  ADD KEY `study_program_id` (`study_program_id`),
+  ADD KEY `cohort_year` (`cohort_year`,`academic_status`);
 
 --
 -- Indexes for table `study_programs`
@@ -329,6 +815,56 @@ ALTER TABLE `study_programs`
   ADD PRIMARY KEY (`id`),
   ADD KEY `major_id` (`major_id`);
 
+--
+-- Indexes for table `user_app_consents`
+--
+ALTER TABLE `user_app_consents`
+  ADD PRIMARY KEY (`user_id`,`application_id`),
+  ADD KEY `application_id` (`application_id`);
+
+--
+-- Indexes for table `user_data_exports`
+--
+ALTER TABLE `user_data_exports`
+  ADD PRIMARY KEY (`id`),
+  ADD UNIQUE KEY `token` (`token`),
+  ADD KEY `user_id` (`user_id`,`requested_at`),
+  ADD KEY `expires_at` (`expires_at`);
+
+--
+-- Indexes for table `user_favorite_apps`
+--
+ALTER TABLE `user_favorite_apps`
+  ADD PRIMARY KEY (`user_id`,`application_id`),
+  ADD KEY `application_id` (`application_id`);
+
+--
+-- Indexes for table `user_group_members`
+--
+ALTER TABLE `user_group_members`
+  ADD PRIMARY KEY (`group_id`,`user_id`),
+  ADD KEY `user_id` (`user_id`),
+  ADD KEY `added_by` (`added_by`);
+
+--
+-- Indexes for table `user_groups`
+--
+ALTER TABLE `user_groups`
+  ADD PRIMARY KEY (`id`),
+  ADD UNIQUE KEY `slug` (`slug`),
+  ADD KEY `rule_major_id` (`rule_major_id`),
+  ADD KEY `rule_study_program_id` (`rule_study_program_id`),
+  ADD KEY `rule_position_id` (`rule_position_id`);
+
+--
+-- Indexes for table `user_logins`
+--
+ALTER TABLE `user_logins`
+  ADD PRIMARY KEY (`id`),
+  ADD KEY `logged_in_at` (`logged_in_at`),
+  ADD KEY `user_id` (`user_id`,`logged_in_at`),
+  ADD KEY `role_id` (`role_id`);
+
 --
 -- Indexes for table `users`
 --
@@ -355,6 +891,66 @@ ALTER TABLE `user_roles`
 -- AUTO_INCREMENT for dumped tables
 --
 
+--
+-- AUTO_INCREMENT for table `access_requests`
+--
+ALTER TABLE `access_requests`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `admin_scopes`
+--
+ALTER TABLE `admin_scopes`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_availability_windows`
+--
+ALTER TABLE `application_availability_windows`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_deep_link_prefixes`
+--
+ALTER TABLE `application_deep_link_prefixes`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_health_checks`
+--
+ALTER TABLE `application_health_checks`
+  MODIFY `id` bigint NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_launches`
+--
+ALTER TABLE `application_launches`
+  MODIFY `id` bigint NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_policies`
+--
+ALTER TABLE `application_policies`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_position_access`
+--
+ALTER TABLE `application_position_access`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_student_access`
+--
+ALTER TABLE `application_student_access`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `application_user_overrides`
+--
+ALTER TABLE `application_user_overrides`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
 --
 -- AUTO_INCREMENT for table `applications`
 --
@@ -397,6 +993,18 @@ ALTER TABLE `majors`
 ALTER TABLE `positions`
   MODIFY `id` int NOT NULL AUTO_INCREMENT;
 
+--
+-- AUTO_INCREMENT for table `provisioning_rules`
+--
+ALTER TABLE `provisioning_rules`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `registration_requests`
+--
+ALTER TABLE `registration_requests`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
 --
 -- AUTO_INCREMENT for table `roles`
 --
@@ -415,6 +1023,24 @@ ALTER TABLE `students`
 ALTER TABLE `study_programs`
   MODIFY `id` int NOT NULL AUTO_INCREMENT;
 
+--
+-- AUTO_INCREMENT for table `user_data_exports`
+--
+ALTER TABLE `user_data_exports`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `user_groups`
+--
+ALTER TABLE `user_groups`
+  MODIFY `id` int NOT NULL AUTO_INCREMENT;
+
+--
+-- AUTO_INCREMENT for table `user_logins`
+--
+ALTER TABLE `user_logins`
+  MODIFY `id` bigint NOT NULL AUTO_INCREMENT;
+
 --
 -- AUTO_INCREMENT for table `users`
 --
@@ -431,6 +1057,91 @@ ALTER TABLE `user_push_subscriptions`
 -- Constraints for dumped tables
 --
 
+--
+-- Constraints for table `access_requests`
+--
+ALTER TABLE `access_requests`
+  ADD CONSTRAINT `access_requests_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `access_requests_ibfk_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `access_requests_ibfk_3` FOREIGN KEY (`reviewed_by`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
+--
+-- Constraints for table `admin_scopes`
+--
+ALTER TABLE `admin_scopes`
+  ADD CONSTRAINT `admin_scopes_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `admin_scopes_ibfk_2` FOREIGN KEY (`major_id`) REFERENCES `majors` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `admin_scopes_ibfk_3` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `application_approvers`
+--
+ALTER TABLE `application_approvers`
+  ADD CONSTRAINT `application_approvers_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_approvers_ibfk_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_approvers_ibfk_3` FOREIGN KEY (`added_by`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
+--
+-- Constraints for table `application_availability_windows`
+--
+ALTER TABLE `application_availability_windows`
+  ADD CONSTRAINT `application_availability_windows_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_availability_windows_ibfk_2` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+
+--
+-- Constraints for table `application_deep_link_prefixes`
+--
+ALTER TABLE `application_deep_link_prefixes`
+  ADD CONSTRAINT `application_deep_link_prefixes_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `application_group_access`
+--
+ALTER TABLE `application_group_access`
+  ADD CONSTRAINT `application_group_access_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_group_access_ibfk_2` FOREIGN KEY (`group_id`) REFERENCES `user_groups` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+
+--
+-- Constraints for table `application_health_checks`
+--
+ALTER TABLE `application_health_checks`
+  ADD CONSTRAINT `application_health_checks_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+
+--
+-- Constraints for table `application_launches`
+--
+ALTER TABLE `application_launches`
+  ADD CONSTRAINT `application_launches_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `application_launches_ibfk_2` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `application_launches_ibfk_3` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE SET NULL;
+
+--
+-- Constraints for table `application_policies`
+--
+ALTER TABLE `application_policies`
+  ADD CONSTRAINT `application_policies_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_policies_ibfk_2` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_policies_ibfk_3` FOREIGN KEY (`position_id`) REFERENCES `positions` (`id`) ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_policies_ibfk_4` FOREIGN KEY (`major_id`) REFERENCES `majors` (`id`) ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_policies_ibfk_5` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_policies_ibfk_6` FOREIGN KEY (`group_id`) REFERENCES `user_groups` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+
+--
+-- Constraints for table `application_student_access`
+--
+ALTER TABLE `application_student_access`
+  ADD CONSTRAINT `application_student_access_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_student_access_ibfk_2` FOREIGN KEY (`major_id`) REFERENCES `majors` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_student_access_ibfk_3` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+
+--
+-- Constraints for table `application_user_overrides`
+--
+ALTER TABLE `application_user_overrides`
+  ADD CONSTRAINT `application_user_overrides_ibfk_1` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_user_overrides_ibfk_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_user_overrides_ibfk_3` FOREIGN KEY (`created_by`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
 --
 -- Constraints for table `applications`
 --
@@ -449,7 +1160,9 @@ ALTER TABLE `application_notifications`
 --
 ALTER TABLE `application_position_access`
   ADD CONSTRAINT `application_position_access_ibfk_1` FOREIGN KEY (`position_id`) REFERENCES `positions` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
-  ADD CONSTRAINT `application_position_access_ibfk_2` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+  ADD CONSTRAINT `application_position_access_ibfk_2` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_position_access_ibfk_3` FOREIGN KEY (`major_id`) REFERENCES `majors` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `application_position_access_ibfk_4` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
 
 --
 -- Constraints for table `application_role_access`
@@ -462,7 +1175,8 @@ ALTER TABLE `application_role_access`
 -- Constraints for table `lecturers`
 --
 ALTER TABLE `lecturers`
-  ADD CONSTRAINT `lecturers_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+  ADD CONSTRAINT `lecturers_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `lecturers_ibfk_2` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON DELETE SET NULL;
 
 --
 -- Constraints for table `lecturer_positions`
@@ -472,11 +1186,39 @@ ALTER TABLE `lecturer_positions`
   ADD CONSTRAINT `lecturer_positions_ibfk_2` FOREIGN KEY (`position_id`) REFERENCES `positions` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
   ADD CONSTRAINT `lecturer_positions_ibfk_3` FOREIGN KEY (`major_id`) REFERENCES `majors` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
 
+--
+-- Constraints for table `provisioning_rules`
+--
+ALTER TABLE `provisioning_rules`
+  ADD CONSTRAINT `provisioning_rules_ibfk_1` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `registration_requests`
+--
+ALTER TABLE `registration_requests`
+  ADD CONSTRAINT `registration_requests_ibfk_1` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `registration_requests_ibfk_2` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON DELETE SET NULL;
+
+--
+-- Constraints for table `role_permissions`
+--
+ALTER TABLE `role_permissions`
+  ADD CONSTRAINT `role_permissions_ibfk_1` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `student_advisors`
+--
+ALTER TABLE `student_advisors`
+  ADD CONSTRAINT `student_advisors_ibfk_1` FOREIGN KEY (`student_user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `student_advisors_ibfk_2` FOREIGN KEY (`advisor_user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `student_advisors_ibfk_3` FOREIGN KEY (`assigned_by`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
 --
 -- Constraints for table `students`
 --
 ALTER TABLE `students`
-  ADD CONSTRAINT `students_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+  ADD CONSTRAINT `students_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `students_ibfk_2` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs` (`id`) ON DELETE SET NULL;
 
 --
 -- Constraints for table `study_programs`
@@ -484,6 +1226,49 @@ ALTER TABLE `students`
 ALTER TABLE `study_programs`
   ADD CONSTRAINT `study_programs_ibfk_1` FOREIGN KEY (`major_id`) REFERENCES `majors` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
 
+--
+-- Constraints for table `user_app_consents`
+--
+ALTER TABLE `user_app_consents`
+  ADD CONSTRAINT `user_app_consents_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `user_app_consents_ibfk_2` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `user_data_exports`
+--
+ALTER TABLE `user_data_exports`
+  ADD CONSTRAINT `user_data_exports_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `user_favorite_apps`
+--
+ALTER TABLE `user_favorite_apps`
+  ADD CONSTRAINT `user_favorite_apps_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `user_favorite_apps_ibfk_2` FOREIGN KEY (`application_id`) REFERENCES `applications` (`id`) ON DELETE CASCADE;
+
+--
+-- Constraints for table `user_group_members`
+--
+ALTER TABLE `user_group_members`
+  ADD CONSTRAINT `user_group_members_ibfk_1` FOREIGN KEY (`group_id`) REFERENCES `user_groups` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `user_group_members_ibfk_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  ADD CONSTRAINT `user_group_members_ibfk_3` FOREIGN KEY (`added_by`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
+--
+-- Constraints for table `user_groups`
+--
+ALTER TABLE `user_groups`
+  ADD CONSTRAINT `user_groups_ibfk_1` FOREIGN KEY (`rule_major_id`) REFERENCES `majors` (`id`) ON UPDATE CASCADE,
+  ADD CONSTRAINT `user_groups_ibfk_2` FOREIGN KEY (`rule_study_program_id`) REFERENCES `study_programs` (`id`) ON UPDATE CASCADE,
+  ADD CONSTRAINT `user_groups_ibfk_3` FOREIGN KEY (`rule_position_id`) REFERENCES `positions` (`id`) ON UPDATE CASCADE;
+
+--
+-- Constraints for table `user_logins`
+--
+ALTER TABLE `user_logins`
+  ADD CONSTRAINT `user_logins_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `user_logins_ibfk_2` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE SET NULL;
+
 --
 -- Constraints for table `user_push_subscriptions`
 --
@@ -6,12 +6,16 @@ import (
 	"net/http"
 	"os"
 	"sso-portal-v5/config"
+	"sso-portal-v5/controllers/accessrequestcontroller"
 	"sso-portal-v5/controllers/admincontroller"
+	"sso-portal-v5/controllers/apicontroller"
 	"sso-portal-v5/controllers/authcontroller"
 	"sso-portal-v5/controllers/dashboardcontroller"
 	"sso-portal-v5/controllers/redirectcontroller"
 	"sso-portal-v5/controllers/usercontroller"
 	"sso-portal-v5/middleware"
+	"sso-portal-v5/models"
+	"sso-portal-v5/services"
 	"sso-portal-v5/views"
 	"time"
 
@@ -64,12 +68,18 @@ func main() {
     log.Fatalf("Gagal memuat RSA keys: %v", err)
 	}
 
+	// Jalankan job terjadwal (pengingat & penutupan masa jabatan)
+	scheduler := services.StartScheduler(env)
+	defer scheduler.Stop()
+
 	// Inisialisasi controller
 	authCtrl := authcontroller.NewAuthController(env, viewEngine)
 	dashboardCtrl := dashboardcontroller.NewDashboardController(env, viewEngine)
 	adminCtrl := admincontroller.NewAdminController(env, viewEngine)
 	redirectCtrl := redirectcontroller.NewRedirectController(env, viewEngine)
 	userCtrl := usercontroller.NewUserController(env, viewEngine)
+	apiCtrl := apicontroller.NewAPIController(env)
+	accessRequestCtrl := accessrequestcontroller.NewAccessRequestController(env, viewEngine)
 
 	// Setup Router
 	r := mux.NewRouter()
@@ -93,6 +103,8 @@ func main() {
 	r.HandleFunc("/auth/google/login", authCtrl.LoginWithGoogle).Methods("GET")
 	r.HandleFunc("/auth/google/callback", authCtrl.GoogleCallback).Methods("GET")
 	r.HandleFunc("/logout", authCtrl.Logout).Methods("GET")
+	r.HandleFunc("/register", authCtrl.ShowRegisterForm).Methods("GET")
+	r.HandleFunc("/register/submit", authCtrl.SubmitRegistration).Methods("POST")
 
 	protected := r.NewRoute().Subrouter()
 	protected.Use(middleware.GlobalAuthMiddleware(env))
@@ -100,97 +112,193 @@ func main() {
 	// DASHBOARD ROUTES
 	// ====================================
 	protected.HandleFunc("/dashboard", dashboardCtrl.Index).Methods("GET")
+	protected.HandleFunc("/favorites/add/{id}", dashboardCtrl.AddFavorite).Methods("POST")
+	protected.HandleFunc("/favorites/remove/{id}", dashboardCtrl.RemoveFavorite).Methods("POST")
 
 	// ===================================
 	// USER ROUTES
 	// ====================================
 	protected.HandleFunc("/profile/edit", userCtrl.ShowProfileForm).Methods("GET")
 	protected.HandleFunc("/profile/update", userCtrl.HandleProfileUpdate).Methods("POST")
+	protected.HandleFunc("/profile/connected-apps", userCtrl.ShowConnectedApps).Methods("GET")
+	protected.HandleFunc("/profile/connected-apps/revoke/{id}", userCtrl.RevokeConnectedApp).Methods("POST")
+	protected.HandleFunc("/profile/data-export", userCtrl.ShowDataExport).Methods("GET")
+	protected.HandleFunc("/profile/data-export", userCtrl.RequestDataExport).Methods("POST")
+	protected.HandleFunc("/profile/data-export/download/{token}", userCtrl.DownloadDataExport).Methods("GET")
 	r.HandleFunc("/avatar/{userID}", userCtrl.ServeAvatar).Methods("GET")
 
+	// ===================================
+	// ACCESS REQUEST ROUTES
+	// ====================================
+	protected.HandleFunc("/access-requests", accessRequestCtrl.Index).Methods("GET")
+	protected.HandleFunc("/access-requests/submit", accessRequestCtrl.Submit).Methods("POST")
+	protected.HandleFunc("/access-requests/review", accessRequestCtrl.Review).Methods("GET")
+	protected.HandleFunc("/access-requests/approve/{id}", accessRequestCtrl.Approve).Methods("POST")
+	protected.HandleFunc("/access-requests/deny/{id}", accessRequestCtrl.Deny).Methods("POST")
+
 	// ===================================
 	// REDIRECT MANAGEMENT
 	// ====================================
 	protected.HandleFunc("/redirect", redirectCtrl.RedirectToApp).Methods("GET")
+	protected.HandleFunc("/redirect/consent", redirectCtrl.GrantConsent).Methods("POST")
+
+	// ===================================
+	// CLIENT APP API (autentikasi dengan token JWT portal)
+	// ====================================
+	r.HandleFunc("/api/advisees", apiCtrl.ListAdvisees).Methods("GET")
 
 	// ===================================
 	// ADMIN ROUTES
+	// Setiap route dicek per izin (role_permissions). Admin terdelegasi (jurusan/prodi)
+	// mendapat izin pengguna & prodi dengan data yang disaring sesuai lingkup di controller.
 	// ====================================
 	adminRouter := protected.PathPrefix("/admin").Subrouter()
-	adminRouter.Use(middleware.AdminMiddleware(env, viewEngine))
+	adminRouter.Use(middleware.AdminAccessMiddleware(env, viewEngine))
+	can := middleware.RequirePermission(viewEngine)
 	adminRouter.HandleFunc("/dashboard", adminCtrl.Dashboard).Methods("GET")
+	adminRouter.Handle("/analytics/activity", can(models.PermAppsRead, adminCtrl.AnalyticsActivity)).Methods("GET")
+	adminRouter.Handle("/analytics/top-apps", can(models.PermAppsRead, adminCtrl.AnalyticsTopApps)).Methods("GET")
+	adminRouter.Handle("/analytics/launches", can(models.PermAppsRead, adminCtrl.AnalyticsLaunches)).Methods("GET")
 
 	// ===================================
 	// USER MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/users", adminCtrl.ListUsers).Methods("GET")
-	adminRouter.HandleFunc("/user/detail/{id}", adminCtrl.DetailUser).Methods("GET")
-	adminRouter.HandleFunc("/user/edit/{id}", adminCtrl.EditUserForm).Methods("GET")
-	adminRouter.HandleFunc("/user/update/{id}", adminCtrl.UpdateUser).Methods("POST")
-	adminRouter.HandleFunc("/user/delete/{id}", adminCtrl.DeleteUser).Methods("POST")
-	adminRouter.HandleFunc("/user/new", adminCtrl.NewUserForm).Methods("GET")
-	adminRouter.HandleFunc("/user/create", adminCtrl.CreateUser).Methods("POST")
+	adminRouter.Handle("/users", can(models.PermUsersRead, adminCtrl.ListUsers)).Methods("GET")
+	adminRouter.Handle("/users/export", can(models.PermUsersRead, adminCtrl.ExportUsers)).Methods("GET")
+	adminRouter.Handle("/user/detail/{id}", can(models.PermUsersRead, adminCtrl.DetailUser)).Methods("GET")
+	adminRouter.Handle("/user/status/{id}", can(models.PermUsersStatus, adminCtrl.UpdateUserStatus)).Methods("POST")
+	adminRouter.Handle("/user/edit/{id}", can(models.PermUsersWrite, adminCtrl.EditUserForm)).Methods("GET")
+	adminRouter.Handle("/user/update/{id}", can(models.PermUsersWrite, adminCtrl.UpdateUser)).Methods("POST")
+	adminRouter.Handle("/user/delete/{id}", can(models.PermUsersWrite, adminCtrl.DeleteUser)).Methods("POST")
+	adminRouter.Handle("/user/new", can(models.PermUsersWrite, adminCtrl.NewUserForm)).Methods("GET")
+	adminRouter.Handle("/user/create", can(models.PermUsersWrite, adminCtrl.CreateUser)).Methods("POST")
+	adminRouter.Handle("/user/import", can(models.PermUsersWrite, adminCtrl.ImportUserForm)).Methods("GET")
+	adminRouter.Handle("/user/import/template", can(models.PermUsersWrite, adminCtrl.DownloadImportTemplate)).Methods("GET")
+	adminRouter.Handle("/user/import/preview", can(models.PermUsersWrite, adminCtrl.PreviewImportUser)).Methods("POST")
+	adminRouter.Handle("/user/import/commit", can(models.PermUsersWrite, adminCtrl.CommitImportUser)).Methods("POST")
+	adminRouter.Handle("/user/scope/add/{id}", can(models.PermRolesWrite, adminCtrl.AddAdminScope)).Methods("POST")
+	adminRouter.Handle("/user/scope/delete/{id}", can(models.PermRolesWrite, adminCtrl.DeleteAdminScope)).Methods("POST")
+	adminRouter.Handle("/user/advisor/{id}", can(models.PermUsersWrite, adminCtrl.SetUserAdvisor)).Methods("POST")
+	adminRouter.Handle("/advisors", can(models.PermUsersWrite, adminCtrl.AdvisorBulkForm)).Methods("GET")
+	adminRouter.Handle("/advisors/assign", can(models.PermUsersWrite, adminCtrl.BulkAssignAdvisors)).Methods("POST")
+
+	// ===================================
+	// USER GROUPS
+	// ====================================
+	adminRouter.Handle("/groups", can(models.PermGroupsWrite, adminCtrl.ListGroups)).Methods("GET")
+	adminRouter.Handle("/group/new", can(models.PermGroupsWrite, adminCtrl.NewGroupForm)).Methods("GET")
+	adminRouter.Handle("/group/create", can(models.PermGroupsWrite, adminCtrl.CreateGroup)).Methods("POST")
+	adminRouter.Handle("/group/preview", can(models.PermGroupsWrite, adminCtrl.PreviewGroup)).Methods("POST")
+	adminRouter.Handle("/group/detail/{id}", can(models.PermGroupsWrite, adminCtrl.DetailGroup)).Methods("GET")
+	adminRouter.Handle("/group/edit/{id}", can(models.PermGroupsWrite, adminCtrl.EditGroupForm)).Methods("GET")
+	adminRouter.Handle("/group/update/{id}", can(models.PermGroupsWrite, adminCtrl.UpdateGroup)).Methods("POST")
+	adminRouter.Handle("/group/delete/{id}", can(models.PermGroupsWrite, adminCtrl.DeleteGroup)).Methods("POST")
+	adminRouter.Handle("/group/members/add/{id}", can(models.PermGroupsWrite, adminCtrl.AddGroupMembers)).Methods("POST")
+	adminRouter.Handle("/group/members/remove/{id}/{user_id}", can(models.PermGroupsWrite, adminCtrl.RemoveGroupMember)).Methods("POST")
+
+	// ===================================
+	// REGISTRATION REQUESTS
+	// ====================================
+	adminRouter.Handle("/registrations", can(models.PermRegistrationsWrite, adminCtrl.ListRegistrations)).Methods("GET")
+	adminRouter.Handle("/registration/approve/{id}", can(models.PermRegistrationsWrite, adminCtrl.ApproveRegistration)).Methods("POST")
+	adminRouter.Handle("/registration/reject/{id}", can(models.PermRegistrationsWrite, adminCtrl.RejectRegistration)).Methods("POST")
+
+	// ===================================
+	// PROVISIONING RULES
+	// ====================================
+	adminRouter.Handle("/provisioning-rules", can(models.PermRegistrationsWrite, adminCtrl.ListProvisioningRules)).Methods("GET")
+	adminRouter.Handle("/provisioning-rule/new", can(models.PermRegistrationsWrite, adminCtrl.NewProvisioningRuleForm)).Methods("GET")
+	adminRouter.Handle("/provisioning-rule/create", can(models.PermRegistrationsWrite, adminCtrl.CreateProvisioningRule)).Methods("POST")
+	adminRouter.Handle("/provisioning-rule/edit/{id}", can(models.PermRegistrationsWrite, adminCtrl.EditProvisioningRuleForm)).Methods("GET")
+	adminRouter.Handle("/provisioning-rule/update/{id}", can(models.PermRegistrationsWrite, adminCtrl.UpdateProvisioningRule)).Methods("POST")
+	adminRouter.Handle("/provisioning-rule/delete/{id}", can(models.PermRegistrationsWrite, adminCtrl.DeleteProvisioningRule)).Methods("POST")
 
 	// ===================================
 	// APPLICATION MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/applications", adminCtrl.ListApplications).Methods("GET")
-	adminRouter.HandleFunc("/application/detail/{id}", adminCtrl.DetailApplication).Methods("GET")
-	adminRouter.HandleFunc("/application/new", adminCtrl.NewApplicationForm).Methods("GET")
-	adminRouter.HandleFunc("/application/create", adminCtrl.CreateApplication).Methods("POST")
-	adminRouter.HandleFunc("/application/edit/{id}", adminCtrl.EditApplicationForm).Methods("GET")
-	adminRouter.HandleFunc("/application/update/{id}", adminCtrl.UpdateApplication).Methods("POST")
-	adminRouter.HandleFunc("/application/delete/{id}", adminCtrl.DeleteApplication).Methods("POST")
+	adminRouter.Handle("/applications", can(models.PermAppsRead, adminCtrl.ListApplications)).Methods("GET")
+	adminRouter.Handle("/applications/access-matrix", can(models.PermAppsRead, adminCtrl.AccessMatrix)).Methods("GET")
+	adminRouter.Handle("/application/detail/{id}", can(models.PermAppsRead, adminCtrl.DetailApplication)).Methods("GET")
+	adminRouter.Handle("/application/new", can(models.PermAppsWrite, adminCtrl.NewApplicationForm)).Methods("GET")
+	adminRouter.Handle("/application/create", can(models.PermAppsWrite, adminCtrl.CreateApplication)).Methods("POST")
+	adminRouter.Handle("/application/edit/{id}", can(models.PermAppsWrite, adminCtrl.EditApplicationForm)).Methods("GET")
+	adminRouter.Handle("/application/update/{id}", can(models.PermAppsWrite, adminCtrl.UpdateApplication)).Methods("POST")
+	adminRouter.Handle("/application/delete/{id}", can(models.PermAppsWrite, adminCtrl.DeleteApplication)).Methods("POST")
+	adminRouter.Handle("/application/override/{id}", can(models.PermAppAccessWrite, adminCtrl.SetAppOverrideByEmail)).Methods("POST")
+	adminRouter.Handle("/user/app-override/{id}", can(models.PermAppAccessWrite, adminCtrl.SetUserAppOverride)).Methods("POST")
+	adminRouter.Handle("/app-override/delete/{id}", can(models.PermAppAccessWrite, adminCtrl.DeleteAppOverride)).Methods("POST")
+	adminRouter.Handle("/application/approver/add/{id}", can(models.PermAppsWrite, adminCtrl.AddAppApprover)).Methods("POST")
+	adminRouter.Handle("/application/approver/remove/{id}/{user_id}", can(models.PermAppsWrite, adminCtrl.RemoveAppApprover)).Methods("POST")
+	adminRouter.Handle("/application/maintenance/{id}", can(models.PermAppsWrite, adminCtrl.UpdateAppMaintenance)).Methods("POST")
+	adminRouter.Handle("/application/availability/add/{id}", can(models.PermAppsWrite, adminCtrl.AddAvailabilityWindow)).Methods("POST")
+	adminRouter.Handle("/application/availability/delete/{id}", can(models.PermAppsWrite, adminCtrl.DeleteAvailabilityWindow)).Methods("POST")
+	adminRouter.Handle("/application/token-delivery/{id}", can(models.PermAppsWrite, adminCtrl.UpdateAppTokenDelivery)).Methods("POST")
+	adminRouter.Handle("/application/deep-link/add/{id}", can(models.PermAppsWrite, adminCtrl.AddDeepLinkPrefix)).Methods("POST")
+	adminRouter.Handle("/application/deep-link/delete/{id}", can(models.PermAppsWrite, adminCtrl.DeleteDeepLinkPrefix)).Methods("POST")
+	adminRouter.Handle("/application/policies/{id}", can(models.PermAppsWrite, adminCtrl.ListAppPolicies)).Methods("GET")
+	adminRouter.Handle("/application/policy/new/{id}", can(models.PermAppsWrite, adminCtrl.NewPolicyForm)).Methods("GET")
+	adminRouter.Handle("/application/policy/create/{id}", can(models.PermAppsWrite, adminCtrl.CreatePolicy)).Methods("POST")
+	adminRouter.Handle("/policy/edit/{id}", can(models.PermAppsWrite, adminCtrl.EditPolicyForm)).Methods("GET")
+	adminRouter.Handle("/policy/update/{id}", can(models.PermAppsWrite, adminCtrl.UpdatePolicy)).Methods("POST")
+	adminRouter.Handle("/policy/delete/{id}", can(models.PermAppsWrite, adminCtrl.DeletePolicy)).Methods("POST")
+	adminRouter.Handle("/policies/test", can(models.PermAppsRead, adminCtrl.TestPolicy)).Methods("GET")
 
 	// ===================================
 	// MAJOR MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/majors", adminCtrl.ListMajors).Methods("GET")
-	adminRouter.HandleFunc("/major/new", adminCtrl.NewMajorForm).Methods("GET")
-	adminRouter.HandleFunc("/major/create", adminCtrl.CreateMajor).Methods("POST")
-	adminRouter.HandleFunc("/major/edit/{id}", adminCtrl.EditMajorForm).Methods("GET")
-	adminRouter.HandleFunc("/major/update/{id}", adminCtrl.UpdateMajor).Methods("POST")
-	adminRouter.HandleFunc("/major/delete/{id}", adminCtrl.DeleteMajor).Methods("POST")
+	adminRouter.Handle("/majors", can(models.PermMasterWrite, adminCtrl.ListMajors)).Methods("GET")
+	adminRouter.Handle("/major/new", can(models.PermMasterWrite, adminCtrl.NewMajorForm)).Methods("GET")
+	adminRouter.Handle("/major/create", can(models.PermMasterWrite, adminCtrl.CreateMajor)).Methods("POST")
+	adminRouter.Handle("/major/edit/{id}", can(models.PermMasterWrite, adminCtrl.EditMajorForm)).Methods("GET")
+	adminRouter.Handle("/major/update/{id}", can(models.PermMasterWrite, adminCtrl.UpdateMajor)).Methods("POST")
+	adminRouter.Handle("/major/delete/{id}", can(models.PermMasterWrite, adminCtrl.DeleteMajor)).Methods("POST")
 
 	// ===================================
 	// STUDY PROGRAM MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/study-programs", adminCtrl.ListStudyPrograms).Methods("GET")
-	adminRouter.HandleFunc("/study-program/new", adminCtrl.NewStudyProgramForm).Methods("GET")
-	adminRouter.HandleFunc("/study-program/create", adminCtrl.CreateStudyProgram).Methods("POST")
-	adminRouter.HandleFunc("/study-program/edit/{id}", adminCtrl.EditStudyProgramForm).Methods("GET")
-	adminRouter.HandleFunc("/study-program/update/{id}", adminCtrl.UpdateStudyProgram).Methods("POST")
-	adminRouter.HandleFunc("/study-program/delete/{id}", adminCtrl.DeleteStudyProgram).Methods("POST")
+	adminRouter.Handle("/study-programs", can(models.PermStudyProgramsWrite, adminCtrl.ListStudyPrograms)).Methods("GET")
+	adminRouter.Handle("/study-program/new", can(models.PermStudyProgramsWrite, adminCtrl.NewStudyProgramForm)).Methods("GET")
+	adminRouter.Handle("/study-program/create", can(models.PermStudyProgramsWrite, adminCtrl.CreateStudyProgram)).Methods("POST")
+	adminRouter.Handle("/study-program/edit/{id}", can(models.PermStudyProgramsWrite, adminCtrl.EditStudyProgramForm)).Methods("GET")
+	adminRouter.Handle("/study-program/update/{id}", can(models.PermStudyProgramsWrite, adminCtrl.UpdateStudyProgram)).Methods("POST")
+	adminRouter.Handle("/study-program/delete/{id}", can(models.PermStudyProgramsWrite, adminCtrl.DeleteStudyProgram)).Methods("POST")
 
 	// ===================================
 	// POSITION MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/positions", adminCtrl.ListPositions).Methods("GET")
-	adminRouter.HandleFunc("/position/new", adminCtrl.NewPositionForm).Methods("GET")
-	adminRouter.HandleFunc("/position/create", adminCtrl.CreatePosition).Methods("POST")
-	adminRouter.HandleFunc("/position/edit/{id}", adminCtrl.EditPositionForm).Methods("GET")
-	adminRouter.HandleFunc("/position/update/{id}", adminCtrl.UpdatePosition).Methods("POST")
-	adminRouter.HandleFunc("/position/delete/{id}", adminCtrl.DeletePosition).Methods("POST")
+	adminRouter.Handle("/positions", can(models.PermMasterWrite, adminCtrl.ListPositions)).Methods("GET")
+	adminRouter.Handle("/position/new", can(models.PermMasterWrite, adminCtrl.NewPositionForm)).Methods("GET")
+	adminRouter.Handle("/position/create", can(models.PermMasterWrite, adminCtrl.CreatePosition)).Methods("POST")
+	adminRouter.Handle("/position/edit/{id}", can(models.PermMasterWrite, adminCtrl.EditPositionForm)).Methods("GET")
+	adminRouter.Handle("/position/update/{id}", can(models.PermMasterWrite, adminCtrl.UpdatePosition)).Methods("POST")
+	adminRouter.Handle("/position/delete/{id}", can(models.PermMasterWrite, adminCtrl.DeletePosition)).Methods("POST")
 
 	// ===================================
 	// ROLES MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/roles", adminCtrl.ListRoles).Methods("GET")
-	adminRouter.HandleFunc("/role/new", adminCtrl.NewRoleForm).Methods("GET")
-	adminRouter.HandleFunc("/role/create", adminCtrl.CreateRole).Methods("POST")
-	adminRouter.HandleFunc("/role/edit/{id}", adminCtrl.EditRoleForm).Methods("GET")
-	adminRouter.HandleFunc("/role/update/{id}", adminCtrl.UpdateRole).Methods("POST")
-	adminRouter.HandleFunc("/role/delete/{id}", adminCtrl.DeleteRole).Methods("POST")
+	adminRouter.Handle("/roles", can(models.PermRolesWrite, adminCtrl.ListRoles)).Methods("GET")
+	adminRouter.Handle("/role/new", can(models.PermRolesWrite, adminCtrl.NewRoleForm)).Methods("GET")
+	adminRouter.Handle("/role/create", can(models.PermRolesWrite, adminCtrl.CreateRole)).Methods("POST")
+	adminRouter.Handle("/role/edit/{id}", can(models.PermRolesWrite, adminCtrl.EditRoleForm)).Methods("GET")
+	adminRouter.Handle("/role/update/{id}", can(models.PermRolesWrite, adminCtrl.UpdateRole)).Methods("POST")
+	adminRouter.Handle("/role/delete/{id}", can(models.PermRolesWrite, adminCtrl.DeleteRole)).Methods("POST")
 
 	// ===================================
 	// CATEGORIES MANAGEMENT
 	// ====================================
-	adminRouter.HandleFunc("/categories", adminCtrl.ListCategories).Methods("GET")
-	adminRouter.HandleFunc("/category/new", adminCtrl.NewCategoriesForm).Methods("GET")
-	adminRouter.HandleFunc("/category/create", adminCtrl.CreateCategory).Methods("POST")
-	adminRouter.HandleFunc("/category/edit/{id}", adminCtrl.EditCategoriesForm).Methods("GET")
-	adminRouter.HandleFunc("/category/update/{id}", adminCtrl.UpdateCategory).Methods("POST")
-	adminRouter.HandleFunc("/category/delete/{id}", adminCtrl.DeleteCategory).Methods("POST")
+	adminRouter.Handle("/categories", can(models.PermCategoriesWrite, adminCtrl.ListCategories)).Methods("GET")
+	adminRouter.Handle("/category/new", can(models.PermCategoriesWrite, adminCtrl.NewCategoriesForm)).Methods("GET")
+	adminRouter.Handle("/category/create", can(models.PermCategoriesWrite, adminCtrl.CreateCategory)).Methods("POST")
+	adminRouter.Handle("/category/edit/{id}", can(models.PermCategoriesWrite, adminCtrl.EditCategoriesForm)).Methods("GET")
+	adminRouter.Handle("/category/update/{id}", can(models.PermCategoriesWrite, adminCtrl.UpdateCategory)).Methods("POST")
+	adminRouter.Handle("/category/delete/{id}", can(models.PermCategoriesWrite, adminCtrl.DeleteCategory)).Methods("POST")
+
+	// ===================================
+	// TRASH
+	// ====================================
+	adminRouter.Handle("/trash", can(models.PermTrashWrite, adminCtrl.ListTrash)).Methods("GET")
+	adminRouter.Handle("/trash/restore/{entity}/{id}", can(models.PermTrashWrite, adminCtrl.RestoreTrash)).Methods("POST")
+	adminRouter.Handle("/trash/purge/{entity}/{id}", can(models.PermTrashWrite, adminCtrl.PurgeTrash)).Methods("POST")
 
 	// ===================================
 	// API ROUTES
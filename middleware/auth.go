@@ -41,6 +41,17 @@ func GlobalAuthMiddleware(env *config.Env) func(http.Handler) http.Handler {
 				return
 			}
 
+			if user == nil {
+				session.Values["authenticated"] = false
+				delete(session.Values, "user_id")
+				session.Options.MaxAge = -1
+
+				session.AddFlash("Akun Anda tidak ditemukan. Silahkan Hubungi Administrator.")
+				session.Save(r, w)
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+				return
+			}
+
 			if user.Status != "aktif" {
 				session.Values["authenticated"] = false
                 delete(session.Values, "user_id")
@@ -62,24 +73,61 @@ func GlobalAuthMiddleware(env *config.Env) func(http.Handler) http.Handler {
 	}
 }
 
-func AdminMiddleware(env *config.Env, v *views.Views) func(http.Handler) http.Handler {
-    return func(next http.Handler) http.Handler {
-        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-
-            roleVal := r.Context().Value("ActiveRole")
-            role, ok := roleVal.(string)
-
-            if !ok || role != "admin" {
-                w.WriteHeader(http.StatusForbidden)
-                data := map[string]interface{}{
-                    "Code":    http.StatusForbidden,
-                    "Message": "Akses ditolak. Anda tidak memiliki izin Administrator untuk mengakses halaman ini.",
-                }
-                v.RenderPage(w, r, "error", data) 
-                return
-            }
-
-            next.ServeHTTP(w, r)
-        })
-    }
+// AdminAccessMiddleware memuat hak akses panel admin (izin dan lingkup) ke context dengan key "AdminAccess".
+// User tanpa izin admin maupun lingkup terdelegasi ditolak.
+func AdminAccessMiddleware(env *config.Env, v *views.Views) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			user, ok := r.Context().Value("UserLogin").(*models.FullUser)
+			if !ok {
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+				return
+			}
+
+			access, err := models.LoadAdminAccess(env.DB, user)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				v.RenderPage(w, r, "error", map[string]interface{}{
+					"Code":    http.StatusInternalServerError,
+					"Message": "Terjadi Kesalahan Pada Sistem, Silahkan Hubungi Administrator.",
+				})
+				log.Printf("CRITICAL ERROR path=%s, err=%v", r.URL.Path, err)
+				return
+			}
+
+			if access == nil {
+				w.WriteHeader(http.StatusForbidden)
+				v.RenderPage(w, r, "error", map[string]interface{}{
+					"Code":    http.StatusForbidden,
+					"Message": "Akses ditolak. Anda tidak memiliki izin Administrator untuk mengakses halaman ini.",
+				})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "AdminAccess", access)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission membungkus handler admin agar hanya bisa diakses user dengan izin tertentu.
+// Dipakai per route di bawah AdminAccessMiddleware.
+func RequirePermission(v *views.Views) func(perm string, h http.HandlerFunc) http.Handler {
+	return func(perm string, h http.HandlerFunc) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			access, ok := r.Context().Value("AdminAccess").(*models.AdminAccess)
+			if !ok || !access.Can(perm) {
+				w.WriteHeader(http.StatusForbidden)
+				v.RenderPage(w, r, "error", map[string]interface{}{
+					"Code":    http.StatusForbidden,
+					"Message": "Akses ditolak. Anda tidak memiliki izin untuk membuka halaman ini.",
+				})
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
 }
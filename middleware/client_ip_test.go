@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.1, 172.16.0.0/12, ::1, bukan-ip")
+	os.Exit(m.Run())
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		realIP     string
+		want       string
+	}{
+		{"tanpa proxy", "203.0.113.5:5000", nil, "", "203.0.113.5"},
+		{"header dari klien langsung diabaikan", "203.0.113.5:5000", []string{"1.2.3.4"}, "1.2.3.4", "203.0.113.5"},
+		{"lewat proxy terpercaya", "10.0.0.1:5000", []string{"198.51.100.7"}, "", "198.51.100.7"},
+		{"hop kiri palsu diabaikan", "10.0.0.1:5000", []string{"1.2.3.4, 198.51.100.7"}, "", "198.51.100.7"},
+		{"beberapa proxy terpercaya", "10.0.0.1:5000", []string{"198.51.100.7, 172.16.4.2"}, "", "198.51.100.7"},
+		{"header XFF berulang", "10.0.0.1:5000", []string{"1.2.3.4", "198.51.100.7"}, "", "198.51.100.7"},
+		{"hop tidak valid", "10.0.0.1:5000", []string{"198.51.100.7, sampah"}, "", "10.0.0.1"},
+		{"semua hop terpercaya", "10.0.0.1:5000", []string{"172.16.0.9"}, "", "10.0.0.1"},
+		{"X-Real-Ip tanpa XFF", "10.0.0.1:5000", nil, "198.51.100.8", "198.51.100.8"},
+		{"X-Real-Ip diabaikan jika ada XFF", "10.0.0.1:5000", []string{"198.51.100.7"}, "198.51.100.8", "198.51.100.7"},
+		{"X-Real-Ip tidak valid", "10.0.0.1:5000", nil, "sampah", "10.0.0.1"},
+		{"proxy IPv6", "[::1]:5000", []string{"2001:db8::1"}, "", "2001:db8::1"},
+		{"RemoteAddr tanpa port", "203.0.113.5", nil, "", "203.0.113.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-Ip", tt.realIP)
+			}
+
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
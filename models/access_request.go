@@ -0,0 +1,256 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrAccessRequestBlocked dikembalikan saat pengajuan disetujui untuk user yang diblokir administrator dari aplikasi.
+var ErrAccessRequestBlocked = errors.New("user diblokir dari aplikasi ini")
+
+// AccessRequest adalah pengajuan akses user ke aplikasi yang belum dapat ia buka.
+// Jika disetujui, user mendapat pengecualian allow sampai GrantExpiresAt (inklusif, kosong berarti permanen).
+type AccessRequest struct {
+	ID             int            `db:"id"`
+	ApplicationID  int            `db:"application_id"`
+	AppName        string         `db:"app_name"`
+	UserID         int            `db:"user_id"`
+	UserName       string         `db:"user_name"`
+	UserEmail      string         `db:"user_email"`
+	RoleName       string         `db:"role_name"`
+	Justification  string         `db:"justification"`
+	Status         string         `db:"status"`
+	GrantExpiresAt sql.NullTime   `db:"grant_expires_at"`
+	ReviewNote     sql.NullString `db:"review_note"`
+	ReviewerName   sql.NullString `db:"reviewer_name"`
+	ReviewedAt     sql.NullTime   `db:"reviewed_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+}
+
+// RequestableApp adalah aplikasi pada katalog pengajuan akses.
+type RequestableApp struct {
+	ID          int
+	Name        string
+	Description string
+	IconURL     sql.NullString
+	HasPending  bool
+}
+
+// AppApprover adalah user yang berwenang menyetujui pengajuan akses sebuah aplikasi.
+type AppApprover struct {
+	UserID int    `db:"user_id"`
+	Name   string `db:"name"`
+	Email  string `db:"email"`
+}
+
+const accessRequestSelect = `
+	SELECT ar.id, ar.application_id, a.name AS app_name, ar.user_id, u.name AS user_name, u.email AS user_email,
+		COALESCE((SELECT r.role_name FROM user_roles ur JOIN roles r ON ur.role_id = r.id WHERE ur.user_id = u.id LIMIT 1), '-') AS role_name,
+		ar.justification, ar.status, ar.grant_expires_at, ar.review_note, rv.name AS reviewer_name, ar.reviewed_at, ar.created_at
+	FROM access_requests ar
+	JOIN applications a ON ar.application_id = a.id
+	JOIN users u ON ar.user_id = u.id
+	LEFT JOIN users rv ON ar.reviewed_by = rv.id`
+
+// GetRequestableApps mengambil katalog aplikasi yang dapat diajukan user: aplikasi yang memiliki approver
+// dan belum dapat diakses user. Aplikasi yang diblokir untuknya (pengecualian atau kebijakan deny) tidak ditampilkan.
+func GetRequestableApps(db *sqlx.DB, user *FullUser, ctx AccessContext) ([]RequestableApp, error) {
+	evaluated, _, err := evaluateApps(db, user, ctx, `EXISTS (SELECT 1 FROM application_approvers ap WHERE ap.application_id = a.id)`)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingIDs []int
+	err = db.Select(&pendingIDs, `SELECT application_id FROM access_requests WHERE user_id = ? AND status = 'pending'`, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[int]bool)
+	for _, id := range pendingIDs {
+		pending[id] = true
+	}
+
+	var apps []RequestableApp
+	for _, app := range evaluated {
+		if app.Decision.Source != "none" {
+			continue
+		}
+		apps = append(apps, RequestableApp{
+			ID:          app.ID,
+			Name:        app.Name,
+			Description: app.Description,
+			IconURL:     app.IconURL,
+			HasPending:  pending[app.ID],
+		})
+	}
+	return apps, nil
+}
+
+// GetUserAccessRequests mengambil riwayat pengajuan akses milik user.
+func GetUserAccessRequests(db *sqlx.DB, userID int) ([]AccessRequest, error) {
+	var requests []AccessRequest
+	err := db.Select(&requests, accessRequestSelect+` WHERE ar.user_id = ? ORDER BY ar.created_at DESC LIMIT 50`, userID)
+	return requests, err
+}
+
+// GetApproverAccessRequests mengambil pengajuan pada aplikasi yang dapat direview approver berdasarkan status.
+func GetApproverAccessRequests(db *sqlx.DB, approverID int, status string) ([]AccessRequest, error) {
+	query := accessRequestSelect + `
+	WHERE EXISTS (SELECT 1 FROM application_approvers ap WHERE ap.application_id = ar.application_id AND ap.user_id = ?)`
+	args := []interface{}{approverID}
+
+	if status != "" {
+		query += ` AND ar.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY ar.created_at ASC LIMIT 100`
+
+	var requests []AccessRequest
+	err := db.Select(&requests, query, args...)
+	return requests, err
+}
+
+// CountApproverPendingRequests menghitung pengajuan yang menunggu keputusan approver.
+func CountApproverPendingRequests(db *sqlx.DB, approverID int) (int, error) {
+	var total int
+	err := db.Get(&total, `
+		SELECT COUNT(*) FROM access_requests ar
+		JOIN application_approvers ap ON ap.application_id = ar.application_id AND ap.user_id = ?
+		WHERE ar.status = 'pending'`, approverID)
+	return total, err
+}
+
+func FindAccessRequestByID(db *sqlx.DB, id int) (*AccessRequest, error) {
+	var req AccessRequest
+	err := db.Get(&req, accessRequestSelect+` WHERE ar.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func CreateAccessRequest(db *sqlx.DB, appID, userID int, justification string) (int64, error) {
+	res, err := db.Exec(`INSERT INTO access_requests (application_id, user_id, justification, status, created_at)
+		VALUES (?, ?, ?, 'pending', NOW())`, appID, userID, justification)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ApproveAccessRequest menyetujui pengajuan dan memberi user akses allow sampai expiresAt
+// (format YYYY-MM-DD, kosong berarti permanen) dalam satu transaksi.
+// Hanya pengajuan berstatus pending yang dapat direview.
+func ApproveAccessRequest(db *sqlx.DB, id, reviewerID int, expiresAt, note string) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var req AccessRequest
+	if err = tx.Get(&req, `SELECT id, application_id, user_id, status FROM access_requests WHERE id = ? FOR UPDATE`, id); err != nil {
+		return err
+	}
+	if req.Status != "pending" {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`UPDATE access_requests
+		SET status = 'approved', grant_expires_at = NULLIF(?, ''), review_note = ?, reviewed_by = ?, reviewed_at = NOW()
+		WHERE id = ?`, expiresAt, GetPtr(note), reviewerID, id)
+	if err != nil {
+		return err
+	}
+
+	// Pengecualian yang sudah ada tidak boleh dipersempit: blokir aktif dari admin tetap menang,
+	// dan akses allow yang permanen atau berakhir lebih lambat dipertahankan.
+	var existing struct {
+		ID        int          `db:"id"`
+		Effect    string       `db:"effect"`
+		ExpiresAt sql.NullTime `db:"expires_at"`
+		Active    bool         `db:"active"`
+	}
+	err = tx.Get(&existing, `SELECT id, effect, expires_at, (expires_at IS NULL OR expires_at >= CURDATE()) AS active
+		FROM application_user_overrides WHERE application_id = ? AND user_id = ? FOR UPDATE`, req.ApplicationID, req.UserID)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Exec(`INSERT INTO application_user_overrides (application_id, user_id, effect, reason, expires_at, created_by)
+			VALUES (?, ?, 'allow', ?, NULLIF(?, ''), ?)`,
+			req.ApplicationID, req.UserID, "Pengajuan akses disetujui", expiresAt, reviewerID)
+	case err != nil:
+	case existing.Effect == "deny" && existing.Active:
+		err = ErrAccessRequestBlocked
+	case existing.Effect == "deny":
+		// Blokir yang sudah kedaluwarsa diganti akses dari pengajuan
+		_, err = tx.Exec(`UPDATE application_user_overrides
+			SET effect = 'allow', reason = ?, expires_at = NULLIF(?, ''), created_by = ? WHERE id = ?`,
+			"Pengajuan akses disetujui", expiresAt, reviewerID, existing.ID)
+	case !existing.ExpiresAt.Valid:
+		// Akses allow permanen tidak diubah
+	default:
+		_, err = tx.Exec(`UPDATE application_user_overrides
+			SET expires_at = IF(NULLIF(?, '') IS NULL, NULL, GREATEST(expires_at, CAST(? AS DATE))), reason = ?, created_by = ? WHERE id = ?`,
+			expiresAt, expiresAt, "Pengajuan akses disetujui", reviewerID, existing.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DenyAccessRequest menolak pengajuan akses beserta alasannya.
+func DenyAccessRequest(db *sqlx.DB, id, reviewerID int, note string) error {
+	res, err := db.Exec(`UPDATE access_requests
+		SET status = 'denied', review_note = ?, reviewed_by = ?, reviewed_at = NOW()
+		WHERE id = ? AND status = 'pending'`, GetPtr(note), reviewerID, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetAppApprovers mengambil daftar approver sebuah aplikasi.
+func GetAppApprovers(db *sqlx.DB, appID int) ([]AppApprover, error) {
+	var approvers []AppApprover
+	err := db.Select(&approvers, `
+		SELECT ap.user_id, u.name, u.email
+		FROM application_approvers ap
+		JOIN users u ON ap.user_id = u.id
+		WHERE ap.application_id = ? AND u.deleted_at IS NULL
+		ORDER BY u.name ASC`, appID)
+	return approvers, err
+}
+
+// IsAppApprover mengecek apakah user merupakan approver aplikasi.
+func IsAppApprover(db *sqlx.DB, appID, userID int) (bool, error) {
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM application_approvers WHERE application_id = ? AND user_id = ?`, appID, userID)
+	return count > 0, err
+}
+
+func AddAppApprover(db *sqlx.DB, appID, userID, adminID int) error {
+	_, err := db.Exec(`INSERT IGNORE INTO application_approvers (application_id, user_id, added_by) VALUES (?, ?, ?)`, appID, userID, adminID)
+	return err
+}
+
+func RemoveAppApprover(db *sqlx.DB, appID, userID int) error {
+	_, err := db.Exec(`DELETE FROM application_approvers WHERE application_id = ? AND user_id = ?`, appID, userID)
+	return err
+}
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AdminScope adalah lingkup kelola seorang admin. Izin yang berasal dari role bersifat Global,
+// sedangkan admin terdelegasi (mis. admin jurusan atau Kaprodi) dibatasi pada jurusan/prodi tertentu.
+type AdminScope struct {
+	Global          bool
+	MajorIDs        []int
+	StudyProgramIDs []int // Termasuk prodi di bawah jurusan pada MajorIDs
+}
+
+// AdminScopeGrant adalah satu baris pemberian lingkup admin beserta nama unitnya.
+type AdminScopeGrant struct {
+	ID               int            `db:"id"`
+	UserID           int            `db:"user_id"`
+	MajorID          sql.NullInt64  `db:"major_id"`
+	MajorName        sql.NullString `db:"major_name"`
+	StudyProgramID   sql.NullInt64  `db:"study_program_id"`
+	StudyProgramName sql.NullString `db:"study_program_name"`
+}
+
+func GetAdminScopeGrants(db *sqlx.DB, userID int) ([]AdminScopeGrant, error) {
+	query := `
+		SELECT a.id, a.user_id, a.major_id, m.major_name, a.study_program_id, sp.study_program_name
+		FROM admin_scopes a
+		LEFT JOIN majors m ON a.major_id = m.id AND m.deleted_at IS NULL
+		LEFT JOIN study_programs sp ON a.study_program_id = sp.id AND sp.deleted_at IS NULL
+		WHERE a.user_id = ?
+		ORDER BY a.id ASC`
+
+	var data []AdminScopeGrant
+	err := db.Select(&data, query, userID)
+	return data, err
+}
+
+func AddAdminScopeGrant(db *sqlx.DB, userID int, majorID, studyProgramID *int) error {
+	_, err := db.Exec(`INSERT INTO admin_scopes (user_id, major_id, study_program_id, created_at) VALUES (?, ?, ?, NOW())`,
+		userID, majorID, studyProgramID)
+	return err
+}
+
+func DeleteAdminScopeGrant(db *sqlx.DB, id int) (int, error) {
+	var userID int
+	if err := db.Get(&userID, `SELECT user_id FROM admin_scopes WHERE id = ?`, id); err != nil {
+		return 0, err
+	}
+	_, err := db.Exec(`DELETE FROM admin_scopes WHERE id = ?`, id)
+	return userID, err
+}
+
+// loadDelegatedScope menyusun lingkup admin terdelegasi dari admin_scopes.
+// Mengembalikan nil jika user tidak memiliki lingkup terdelegasi.
+func loadDelegatedScope(db *sqlx.DB, userID int) (*AdminScope, error) {
+	grants, err := GetAdminScopeGrants(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		return nil, nil
+	}
+
+	scope := &AdminScope{}
+	for _, g := range grants {
+		if g.MajorID.Valid && g.MajorName.Valid {
+			scope.MajorIDs = append(scope.MajorIDs, int(g.MajorID.Int64))
+		}
+		if g.StudyProgramID.Valid && g.StudyProgramName.Valid {
+			scope.StudyProgramIDs = append(scope.StudyProgramIDs, int(g.StudyProgramID.Int64))
+		}
+	}
+
+	if len(scope.MajorIDs) > 0 {
+		query, args, err := sqlx.In(`SELECT id FROM study_programs WHERE major_id IN (?) AND deleted_at IS NULL`, scope.MajorIDs)
+		if err != nil {
+			return nil, err
+		}
+		var prodiIDs []int
+		if err := db.Select(&prodiIDs, db.Rebind(query), args...); err != nil {
+			return nil, err
+		}
+		scope.StudyProgramIDs = append(scope.StudyProgramIDs, prodiIDs...)
+	}
+
+	return scope, nil
+}
+
+func (s *AdminScope) AllowsMajor(id int) bool {
+	if s.Global {
+		return true
+	}
+	for _, m := range s.MajorIDs {
+		if m == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AdminScope) AllowsStudyProgram(id int) bool {
+	if s.Global {
+		return true
+	}
+	for _, p := range s.StudyProgramIDs {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterStudyPrograms menyaring daftar prodi sesuai lingkup.
+func (s *AdminScope) FilterStudyPrograms(list []StudyProgram) []StudyProgram {
+	if s.Global {
+		return list
+	}
+	var result []StudyProgram
+	for _, sp := range list {
+		if s.AllowsStudyProgram(sp.ID) {
+			result = append(result, sp)
+		}
+	}
+	return result
+}
+
+// FilterMajors menyaring daftar jurusan sesuai lingkup.
+func (s *AdminScope) FilterMajors(list []Major) []Major {
+	if s.Global {
+		return list
+	}
+	var result []Major
+	for _, m := range list {
+		if s.AllowsMajor(m.ID) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// userCondition menghasilkan kondisi SQL (untuk alias tabel users "u") yang membatasi user
+// pada lingkup baca: mahasiswa/dosen dengan prodi homebase di lingkup, atau dosen yang menjabat di unit lingkup.
+func (s *AdminScope) userCondition() (string, []interface{}, error) {
+	return s.scopedUserCondition(true)
+}
+
+// homebaseUserCondition seperti userCondition tetapi hanya berdasarkan prodi homebase. Dipakai untuk
+// mengubah atau menghapus user: jabatan di unit lingkup tidak membuat dosen dari unit lain dapat dikelola.
+func (s *AdminScope) homebaseUserCondition() (string, []interface{}, error) {
+	return s.scopedUserCondition(false)
+}
+
+func (s *AdminScope) scopedUserCondition(includePositions bool) (string, []interface{}, error) {
+	if s.Global {
+		return "", nil, nil
+	}
+
+	prodis := s.StudyProgramIDs
+	majors := s.MajorIDs
+	// Nilai 0 tidak pernah cocok dengan id, dipakai agar IN () tetap valid saat daftar kosong
+	if len(prodis) == 0 {
+		prodis = []int{0}
+	}
+	if len(majors) == 0 {
+		majors = []int{0}
+	}
+
+	if !includePositions {
+		cond := ` AND (
+		EXISTS (SELECT 1 FROM students s_sc WHERE s_sc.user_id = u.id AND s_sc.study_program_id IN (?))
+		OR EXISTS (SELECT 1 FROM lecturers l_sc WHERE l_sc.user_id = u.id AND l_sc.study_program_id IN (?))
+	) `
+		return sqlx.In(cond, prodis, prodis)
+	}
+
+	cond := ` AND (
+		EXISTS (SELECT 1 FROM students s_sc WHERE s_sc.user_id = u.id AND s_sc.study_program_id IN (?))
+		OR EXISTS (SELECT 1 FROM lecturers l_sc WHERE l_sc.user_id = u.id AND (
+			l_sc.study_program_id IN (?)
+			OR EXISTS (SELECT 1 FROM lecturer_positions lp_sc WHERE lp_sc.lecturer_id = l_sc.id
+				AND (lp_sc.study_program_id IN (?) OR lp_sc.major_id IN (?)))
+		))
+	) `
+
+	return sqlx.In(cond, prodis, prodis, prodis, majors)
+}
+
+// UserInScope mengecek apakah user dengan id tertentu dapat dilihat admin (lingkup baca).
+func UserInScope(db *sqlx.DB, userID int, scope *AdminScope) (bool, error) {
+	if scope.Global {
+		return true, nil
+	}
+	cond, args, err := scope.userCondition()
+	if err != nil {
+		return false, err
+	}
+	return countUserInScope(db, userID, cond, args)
+}
+
+// UserManageableInScope mengecek apakah user dengan id tertentu boleh diubah atau dihapus admin,
+// yaitu prodi homebase-nya berada dalam lingkup.
+func UserManageableInScope(db *sqlx.DB, userID int, scope *AdminScope) (bool, error) {
+	if scope.Global {
+		return true, nil
+	}
+	cond, args, err := scope.homebaseUserCondition()
+	if err != nil {
+		return false, err
+	}
+	return countUserInScope(db, userID, cond, args)
+}
+
+func countUserInScope(db *sqlx.DB, userID int, cond string, args []interface{}) (bool, error) {
+	query := `SELECT COUNT(*) FROM users u WHERE u.id = ?` + cond
+	args = append([]interface{}{userID}, args...)
+
+	var count int
+	err := db.Get(&count, db.Rebind(query), args...)
+	return count > 0, err
+}
+
+// ScopeLabel menampilkan nama unit dari satu grant.
+func (g AdminScopeGrant) ScopeLabel() string {
+	var parts []string
+	if g.MajorName.Valid {
+		parts = append(parts, "Jurusan "+g.MajorName.String)
+	}
+	if g.StudyProgramName.Valid {
+		parts = append(parts, "Prodi "+g.StudyProgramName.String)
+	}
+	if len(parts) == 0 {
+		return "(unit sudah dihapus)"
+	}
+	return strings.Join(parts, ", ")
+}
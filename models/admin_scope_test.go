@@ -0,0 +1,94 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdminScopeAllows(t *testing.T) {
+	global := &AdminScope{Global: true}
+	scoped := &AdminScope{MajorIDs: []int{1}, StudyProgramIDs: []int{10, 11, 20}}
+	empty := &AdminScope{}
+
+	tests := []struct {
+		name        string
+		scope       *AdminScope
+		major, prod int
+		wantMajor   bool
+		wantProdi   bool
+	}{
+		{"global", global, 99, 99, true, true},
+		{"dalam lingkup", scoped, 1, 10, true, true},
+		{"prodi tanpa jurusan", scoped, 2, 20, false, true},
+		{"di luar lingkup", scoped, 2, 30, false, false},
+		{"tanpa lingkup", empty, 1, 10, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.AllowsMajor(tt.major); got != tt.wantMajor {
+				t.Errorf("AllowsMajor(%d) = %v; want %v", tt.major, got, tt.wantMajor)
+			}
+			if got := tt.scope.AllowsStudyProgram(tt.prod); got != tt.wantProdi {
+				t.Errorf("AllowsStudyProgram(%d) = %v; want %v", tt.prod, got, tt.wantProdi)
+			}
+		})
+	}
+}
+
+func TestAdminScopeFilter(t *testing.T) {
+	scope := &AdminScope{MajorIDs: []int{1}, StudyProgramIDs: []int{10}}
+
+	prodis := scope.FilterStudyPrograms([]StudyProgram{{ID: 10}, {ID: 11}})
+	if len(prodis) != 1 || prodis[0].ID != 10 {
+		t.Errorf("FilterStudyPrograms = %+v", prodis)
+	}
+	majors := scope.FilterMajors([]Major{{ID: 1}, {ID: 2}})
+	if len(majors) != 1 || majors[0].ID != 1 {
+		t.Errorf("FilterMajors = %+v", majors)
+	}
+
+	global := &AdminScope{Global: true}
+	if got := global.FilterStudyPrograms([]StudyProgram{{ID: 10}, {ID: 11}}); len(got) != 2 {
+		t.Errorf("FilterStudyPrograms global = %+v", got)
+	}
+}
+
+func TestAdminScopeUserCondition(t *testing.T) {
+	tests := []struct {
+		name             string
+		scope            *AdminScope
+		includePositions bool
+		wantArgs         []interface{}
+		wantPositions    bool
+	}{
+		{"global tanpa kondisi", &AdminScope{Global: true}, true, nil, false},
+		{"lingkup kosong tetap valid", &AdminScope{}, false, []interface{}{0, 0}, false},
+		{"homebase saja", &AdminScope{MajorIDs: []int{1}, StudyProgramIDs: []int{10, 11}}, false,
+			[]interface{}{10, 11, 10, 11}, false},
+		{"baca termasuk jabatan", &AdminScope{MajorIDs: []int{1}, StudyProgramIDs: []int{10}}, true,
+			[]interface{}{10, 10, 10, 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args, err := tt.scope.scopedUserCondition(tt.includePositions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(args) == 0 && len(tt.wantArgs) == 0 {
+				args = nil
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v; want %v", args, tt.wantArgs)
+			}
+			if strings.Count(cond, "?") != len(args) {
+				t.Errorf("placeholder %d, args %d", strings.Count(cond, "?"), len(args))
+			}
+			if got := strings.Contains(cond, "lecturer_positions"); got != tt.wantPositions {
+				t.Errorf("kondisi jabatan = %v; want %v", got, tt.wantPositions)
+			}
+		})
+	}
+}
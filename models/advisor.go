@@ -0,0 +1,149 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Relasi dosen wali disimpan per user id (bukan id students/lecturers) karena baris mahasiswa/dosen
+// dibuat ulang setiap kali data user diedit.
+
+// Advisee adalah mahasiswa bimbingan seorang dosen wali.
+type Advisee struct {
+	UserID           int            `db:"user_id"`
+	Name             string         `db:"name"`
+	Email            string         `db:"email"`
+	NIM              string         `db:"nim"`
+	StudyProgramID   sql.NullInt64  `db:"study_program_id"`
+	StudyProgramName sql.NullString `db:"study_program_name"`
+	CohortYear       sql.NullInt64  `db:"cohort_year"`
+	AcademicStatus   string         `db:"academic_status"`
+}
+
+// LecturerOption adalah pilihan dosen untuk form dosen wali.
+type LecturerOption struct {
+	UserID int            `db:"user_id"`
+	Name   string         `db:"name"`
+	NIP    sql.NullString `db:"nip"`
+}
+
+// GetAdvisees mengambil mahasiswa bimbingan seorang dosen wali.
+func GetAdvisees(db *sqlx.DB, advisorUserID int) ([]Advisee, error) {
+	query := `
+		SELECT u.id AS user_id, u.name, u.email, COALESCE(s.nim, '') AS nim, s.study_program_id, sp.study_program_name,
+			s.cohort_year, s.academic_status
+		FROM student_advisors sa
+		JOIN users u ON sa.student_user_id = u.id AND u.deleted_at IS NULL
+		JOIN students s ON s.user_id = u.id
+		LEFT JOIN study_programs sp ON s.study_program_id = sp.id
+		WHERE sa.advisor_user_id = ?
+		ORDER BY s.cohort_year DESC, u.name ASC`
+
+	var data []Advisee
+	err := db.Select(&data, query, advisorUserID)
+	return data, err
+}
+
+func CountAdvisees(db *sqlx.DB, advisorUserID int) (int, error) {
+	var count int
+	err := db.Get(&count, `
+		SELECT COUNT(*) FROM student_advisors sa
+		JOIN users u ON sa.student_user_id = u.id AND u.deleted_at IS NULL
+		WHERE sa.advisor_user_id = ?`, advisorUserID)
+	return count, err
+}
+
+// FindAdvisorName mengambil nama dosen wali seorang mahasiswa, kosong jika belum ada.
+func FindAdvisorName(db *sqlx.DB, studentUserID int) (int, string, error) {
+	var advisor struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	err := db.Get(&advisor, `
+		SELECT u.id, u.name FROM student_advisors sa
+		JOIN users u ON sa.advisor_user_id = u.id AND u.deleted_at IS NULL
+		WHERE sa.student_user_id = ?`, studentUserID)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	return advisor.ID, advisor.Name, err
+}
+
+// GetLecturerOptions mengambil daftar dosen aktif dalam lingkup admin untuk dipilih sebagai dosen wali.
+func GetLecturerOptions(db *sqlx.DB, scope *AdminScope) ([]LecturerOption, error) {
+	cond, args, err := scope.userCondition()
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT u.id AS user_id, u.name, l.nip
+		FROM users u
+		JOIN lecturers l ON l.user_id = u.id
+		WHERE u.deleted_at IS NULL AND u.status = 'aktif'` + cond + `
+		ORDER BY u.name ASC`
+
+	var data []LecturerOption
+	err = db.Select(&data, db.Rebind(query), args...)
+	return data, err
+}
+
+// IsLecturerUser mengecek apakah user id adalah dosen aktif.
+func IsLecturerUser(db *sqlx.DB, userID int) (bool, error) {
+	var count int
+	err := db.Get(&count, `
+		SELECT COUNT(*) FROM users u JOIN lecturers l ON l.user_id = u.id
+		WHERE u.id = ? AND u.deleted_at IS NULL AND u.status = 'aktif'`, userID)
+	return count > 0, err
+}
+
+// SetStudentAdvisor menetapkan (atau mengganti) dosen wali seorang mahasiswa.
+func SetStudentAdvisor(db *sqlx.DB, studentUserID, advisorUserID, adminID int) error {
+	_, err := db.Exec(upsertAdvisorQuery, studentUserID, advisorUserID, adminID)
+	return err
+}
+
+const upsertAdvisorQuery = `
+	INSERT INTO student_advisors (student_user_id, advisor_user_id, assigned_by, assigned_at)
+	VALUES (?, ?, ?, NOW())
+	ON DUPLICATE KEY UPDATE advisor_user_id = VALUES(advisor_user_id), assigned_by = VALUES(assigned_by), assigned_at = NOW()`
+
+func RemoveStudentAdvisor(db *sqlx.DB, studentUserID int) error {
+	_, err := db.Exec(`DELETE FROM student_advisors WHERE student_user_id = ?`, studentUserID)
+	return err
+}
+
+// BulkAssignAdvisor menetapkan dosen wali untuk semua mahasiswa di satu prodi dan angkatan dalam satu transaksi.
+// Jika onlyUnassigned true, mahasiswa yang sudah memiliki dosen wali dilewati. Mengembalikan jumlah mahasiswa yang diproses.
+func BulkAssignAdvisor(db *sqlx.DB, advisorUserID, studyProgramID, cohortYear int, onlyUnassigned bool, adminID int) (n int, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `
+		SELECT s.user_id FROM students s
+		JOIN users u ON s.user_id = u.id AND u.deleted_at IS NULL
+		WHERE s.study_program_id = ? AND s.cohort_year = ?`
+	if onlyUnassigned {
+		query += ` AND NOT EXISTS (SELECT 1 FROM student_advisors sa WHERE sa.student_user_id = s.user_id)`
+	}
+
+	var studentIDs []int
+	if err = tx.Select(&studentIDs, query, studyProgramID, cohortYear); err != nil {
+		return 0, err
+	}
+
+	for _, id := range studentIDs {
+		if _, err = tx.Exec(upsertAdvisorQuery, id, advisorUserID, adminID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(studentIDs), tx.Commit()
+}
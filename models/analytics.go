@@ -0,0 +1,210 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AnalyticsSummary adalah ringkasan aktivitas portal dalam suatu periode.
+type AnalyticsSummary struct {
+	ActiveUsersToday int `db:"active_users_today" json:"active_users_today"`
+	ActiveUsers      int `db:"active_users" json:"active_users"`
+	Logins           int `db:"logins" json:"logins"`
+	Launches         int `db:"launches" json:"launches"`
+}
+
+// DailyActivity adalah jumlah user aktif, login, dan pembukaan aplikasi pada satu hari.
+type DailyActivity struct {
+	Date        string `json:"date"`
+	ActiveUsers int    `json:"active_users"`
+	Logins      int    `json:"logins"`
+	Launches    int    `json:"launches"`
+}
+
+// AppLaunchStat adalah jumlah pembukaan sebuah aplikasi dan jumlah user berbeda yang membukanya.
+type AppLaunchStat struct {
+	ApplicationID int    `db:"application_id" json:"application_id"`
+	Name          string `db:"name" json:"name"`
+	Launches      int    `db:"launches" json:"launches"`
+	Users         int    `db:"users" json:"users"`
+}
+
+// LaunchBreakdown adalah jumlah pembukaan aplikasi per kelompok (role atau kategori).
+type LaunchBreakdown struct {
+	Label    string `db:"label" json:"label"`
+	Launches int    `db:"launches" json:"launches"`
+	Users    int    `db:"users" json:"users"`
+}
+
+// InactiveUser adalah user aktif yang belum pernah login sejak pencatatan login dimulai.
+type InactiveUser struct {
+	ID        int          `db:"id"`
+	Name      string       `db:"name"`
+	Email     string       `db:"email"`
+	Role      string       `db:"role"`
+	CreatedAt sql.NullTime `db:"created_at"`
+}
+
+// RecordLogin mencatat login user beserta role aktif, IP, dan user agent.
+func RecordLogin(db *sqlx.DB, userID, roleID int, ip, userAgent string) error {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	_, err := db.Exec(`INSERT INTO user_logins (user_id, role_id, ip_address, user_agent) VALUES (?, ?, ?, ?)`,
+		userID, roleID, ip, userAgent)
+	return err
+}
+
+// RecordAppLaunch mencatat user membuka aplikasi melalui portal.
+func RecordAppLaunch(db *sqlx.DB, userID, appID, roleID int) error {
+	_, err := db.Exec(`INSERT INTO application_launches (user_id, application_id, role_id) VALUES (?, ?, ?)`,
+		userID, appID, roleID)
+	return err
+}
+
+// activitySince adalah subquery user yang aktif (login atau membuka aplikasi) sejak N hari lalu.
+const activitySince = `
+	SELECT user_id, logged_in_at AS at FROM user_logins WHERE logged_in_at >= CURDATE() - INTERVAL ? DAY
+	UNION ALL
+	SELECT user_id, launched_at AS at FROM application_launches WHERE launched_at >= CURDATE() - INTERVAL ? DAY`
+
+// GetAnalyticsSummary menghitung ringkasan aktivitas dalam N hari terakhir (termasuk hari ini).
+func GetAnalyticsSummary(db *sqlx.DB, days int) (AnalyticsSummary, error) {
+	since := days - 1
+	var summary AnalyticsSummary
+	err := db.Get(&summary, `
+		SELECT
+			(SELECT COUNT(DISTINCT user_id) FROM (`+activitySince+`) t) AS active_users_today,
+			(SELECT COUNT(DISTINCT user_id) FROM (`+activitySince+`) t) AS active_users,
+			(SELECT COUNT(*) FROM user_logins WHERE logged_in_at >= CURDATE() - INTERVAL ? DAY) AS logins,
+			(SELECT COUNT(*) FROM application_launches WHERE launched_at >= CURDATE() - INTERVAL ? DAY) AS launches`,
+		0, 0, since, since, since, since)
+	return summary, err
+}
+
+// GetDailyActivity mengambil tren aktivitas harian selama N hari terakhir. Hari tanpa aktivitas tetap disertakan dengan nilai nol.
+func GetDailyActivity(db *sqlx.DB, days int) ([]DailyActivity, error) {
+	since := days - 1
+
+	var today string
+	if err := db.Get(&today, `SELECT DATE_FORMAT(CURDATE(), '%Y-%m-%d')`); err != nil {
+		return nil, err
+	}
+	end, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		return nil, err
+	}
+
+	type dayCount struct {
+		Day   string `db:"day"`
+		Total int    `db:"total"`
+	}
+	counts := func(query string, args ...interface{}) (map[string]int, error) {
+		var rows []dayCount
+		if err := db.Select(&rows, query, args...); err != nil {
+			return nil, err
+		}
+		result := make(map[string]int, len(rows))
+		for _, row := range rows {
+			result[row.Day] = row.Total
+		}
+		return result, nil
+	}
+
+	active, err := counts(`
+		SELECT DATE_FORMAT(at, '%Y-%m-%d') AS day, COUNT(DISTINCT user_id) AS total
+		FROM (`+activitySince+`) t GROUP BY day`, since, since)
+	if err != nil {
+		return nil, err
+	}
+	logins, err := counts(`
+		SELECT DATE_FORMAT(logged_in_at, '%Y-%m-%d') AS day, COUNT(*) AS total
+		FROM user_logins WHERE logged_in_at >= CURDATE() - INTERVAL ? DAY GROUP BY day`, since)
+	if err != nil {
+		return nil, err
+	}
+	launches, err := counts(`
+		SELECT DATE_FORMAT(launched_at, '%Y-%m-%d') AS day, COUNT(*) AS total
+		FROM application_launches WHERE launched_at >= CURDATE() - INTERVAL ? DAY GROUP BY day`, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := make([]DailyActivity, 0, days)
+	for d := end.AddDate(0, 0, -since); !d.After(end); d = d.AddDate(0, 0, 1) {
+		day := d.Format("2006-01-02")
+		trend = append(trend, DailyActivity{Date: day, ActiveUsers: active[day], Logins: logins[day], Launches: launches[day]})
+	}
+	return trend, nil
+}
+
+// GetTopApps mengambil aplikasi yang paling sering dibuka dalam N hari terakhir.
+func GetTopApps(db *sqlx.DB, days, limit int) ([]AppLaunchStat, error) {
+	stats := []AppLaunchStat{}
+	err := db.Select(&stats, `
+		SELECT a.id AS application_id, a.name, COUNT(*) AS launches, COUNT(DISTINCT l.user_id) AS users
+		FROM application_launches l
+		JOIN applications a ON l.application_id = a.id
+		WHERE l.launched_at >= CURDATE() - INTERVAL ? DAY
+		GROUP BY a.id, a.name
+		ORDER BY launches DESC, a.name ASC
+		LIMIT ?`, days-1, limit)
+	return stats, err
+}
+
+// GetLaunchesByRole mengelompokkan pembukaan aplikasi dalam N hari terakhir berdasarkan role saat membuka.
+func GetLaunchesByRole(db *sqlx.DB, days int) ([]LaunchBreakdown, error) {
+	stats := []LaunchBreakdown{}
+	err := db.Select(&stats, `
+		SELECT COALESCE(r.role_name, '(role dihapus)') AS label, COUNT(*) AS launches, COUNT(DISTINCT l.user_id) AS users
+		FROM application_launches l
+		LEFT JOIN roles r ON l.role_id = r.id
+		WHERE l.launched_at >= CURDATE() - INTERVAL ? DAY
+		GROUP BY label
+		ORDER BY launches DESC`, days-1)
+	return stats, err
+}
+
+// GetLaunchesByCategory mengelompokkan pembukaan aplikasi dalam N hari terakhir berdasarkan kategori aplikasi.
+func GetLaunchesByCategory(db *sqlx.DB, days int) ([]LaunchBreakdown, error) {
+	stats := []LaunchBreakdown{}
+	err := db.Select(&stats, `
+		SELECT COALESCE(c.name, 'Tanpa Kategori') AS label, COUNT(*) AS launches, COUNT(DISTINCT l.user_id) AS users
+		FROM application_launches l
+		JOIN applications a ON l.application_id = a.id
+		LEFT JOIN categories c ON a.category_id = c.id
+		WHERE l.launched_at >= CURDATE() - INTERVAL ? DAY
+		GROUP BY label
+		ORDER BY launches DESC`, days-1)
+	return stats, err
+}
+
+// GetUsersNeverLoggedIn mengambil user aktif dalam lingkup admin yang belum pernah login,
+// beserta jumlah totalnya. Daftar diurutkan dari akun terlama.
+func GetUsersNeverLoggedIn(db *sqlx.DB, scope *AdminScope, limit int) ([]InactiveUser, int, error) {
+	cond, args, err := scope.userCondition()
+	if err != nil {
+		return nil, 0, err
+	}
+	where := `
+		FROM users u
+		WHERE u.deleted_at IS NULL AND u.status = 'aktif'
+		AND NOT EXISTS (SELECT 1 FROM user_logins ul WHERE ul.user_id = u.id)` + cond
+
+	var total int
+	if err := db.Get(&total, db.Rebind(`SELECT COUNT(*)`+where), args...); err != nil {
+		return nil, 0, err
+	}
+
+	users := []InactiveUser{}
+	query := `
+		SELECT u.id, u.name, u.email, u.created_at,
+			COALESCE((SELECT r.role_name FROM user_roles ur JOIN roles r ON ur.role_id = r.id
+				WHERE ur.user_id = u.id ORDER BY r.id LIMIT 1), '-') AS role` + where + `
+		ORDER BY u.created_at ASC, u.id ASC
+		LIMIT ?`
+	err = db.Select(&users, db.Rebind(query), append(args, limit)...)
+	return users, total, err
+}
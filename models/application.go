@@ -18,6 +18,15 @@ type Application struct {
 	IconURL     sql.NullString `db:"icon_url"`
 	CategoryID  int            `db:"category_id"` 
     CategoryName string        `db:"category_name"`
+    MaintenanceMode    bool           `db:"maintenance_mode"`
+    MaintenanceMessage sql.NullString `db:"maintenance_message"`
+    HealthStatus       string         `db:"health_status"`
+    TokenDelivery      string         `db:"token_delivery"`
+    TokenPostURL       sql.NullString `db:"token_post_url"`
+    RevocationURL      sql.NullString `db:"revocation_url"`
+    AdviseeAPI         bool           `db:"advisee_api"`
+    // Availability diisi saat evaluasi akses user (lihat evaluateApps); nil berarti belum dievaluasi.
+    Availability *AppAvailability `db:"-"`
 }
 
 // GetAllApplications mengambil semua data aplikasi dari database.
@@ -54,8 +63,15 @@ func GetAllApplications(db *sqlx.DB, page int, pagesize int, search string) ([]A
 	return apps, nil
 }
 
+// GetApplicationOptions mengambil id dan nama semua aplikasi untuk pilihan pada form.
+func GetApplicationOptions(db *sqlx.DB) ([]Application, error) {
+	var apps []Application
+	err := db.Select(&apps, `SELECT id, name, slug FROM applications ORDER BY name ASC`)
+	return apps, err
+}
+
 // CreateApplication menyimpan aplikasi baru dan hak akses perannya dalam satu transaksi.
-func CreateApplication(db *sqlx.DB, name, description, slug, targetURL, iconURL string, categoryID int, roleIDs []string, positionIDs []string) error {
+func CreateApplication(db *sqlx.DB, name, description, slug, targetURL, iconURL string, categoryID int, roleIDs []string, positionRules []PositionAccessRule, studentRules []StudentAccessRule, groupIDs []string) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -87,38 +103,46 @@ func CreateApplication(db *sqlx.DB, name, description, slug, targetURL, iconURL
 		}
 	}
 
-	if len(positionIDs) > 0 {
-		stmt, err := tx.Prepare(`INSERT INTO application_position_access (application_id, position_id) VALUES (?, ?)`)
+	if len(positionRules) > 0 {
+		stmt, err := tx.Prepare(`INSERT INTO application_position_access (application_id, position_id, major_id, study_program_id) VALUES (?, ?, ?, ?)`)
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 
-		for _, pid := range positionIDs {
-			_, err := stmt.Exec(appID, pid)
+		for _, rule := range positionRules {
+			_, err := stmt.Exec(appID, rule.PositionID, rule.MajorID, rule.StudyProgramID)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	if err := insertStudentAccessRules(tx, appID, studentRules); err != nil {
+		return err
+	}
+
+	if err := insertGroupAccess(tx, appID, groupIDs); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-// FindApplicationByID mengambil satu aplikasi dan daftar ID peran yang terkait.
-func FindApplicationByID(db *sqlx.DB, id string) (Application, []int, []int, error) {
+// FindApplicationByID mengambil satu aplikasi, daftar ID peran, dan aturan akses jabatan yang terkait.
+func FindApplicationByID(db *sqlx.DB, id string) (Application, []int, []PositionAccessRule, error) {
 	var app Application
 	var roleIDs []int
-	var posIDs []int
 
 	queryApp := `SELECT 
             a.id, a.name, a.description, a.slug, a.target_url, a.icon_url, 
             a.category_id, 
-            COALESCE(c.name, '-') as category_name
+            COALESCE(c.name, '-') as category_name,
+            a.token_delivery, a.token_post_url, a.revocation_url, a.advisee_api
 		FROM applications a
         JOIN categories c ON a.category_id = c.id
 		WHERE a.id = ?`
-	err := db.QueryRow(queryApp, id).Scan(&app.ID, &app.Name, &app.Description, &app.Slug, &app.TargetURL, &app.IconURL, &app.CategoryID, &app.CategoryName)
+	err := db.QueryRow(queryApp, id).Scan(&app.ID, &app.Name, &app.Description, &app.Slug, &app.TargetURL, &app.IconURL, &app.CategoryID, &app.CategoryName, &app.TokenDelivery, &app.TokenPostURL, &app.RevocationURL, &app.AdviseeAPI)
 	if err != nil {
 		return app, nil, nil, err
 	}
@@ -138,26 +162,16 @@ func FindApplicationByID(db *sqlx.DB, id string) (Application, []int, []int, err
 		roleIDs = append(roleIDs, roleID)
 	}
 
-	queryPosition := `SELECT position_id FROM application_position_access WHERE application_id = ?`
-	rows2, err := db.Query(queryPosition, id)
+	positionRules, err := GetPositionAccessRules(db, id)
 	if err != nil {
 		return app, nil, nil, err
 	}
-	defer rows2.Close()
 
-	for rows2.Next() {
-		var posID int
-		if err := rows2.Scan(&posID); err != nil {
-			continue
-		}
-		posIDs = append(posIDs, posID)
-	}
-
-	return app, roleIDs, posIDs, nil
+	return app, roleIDs, positionRules, nil
 }
 
 // UpdateApplication memperbarui data aplikasi dan hak akses perannya dalam satu transaksi.
-func UpdateApplication(db *sqlx.DB, id, name, description, slug, targetURL, iconURL string, categoryID int, roleIDs []string, posIDs []string) error {
+func UpdateApplication(db *sqlx.DB, id, name, description, slug, targetURL, iconURL string, categoryID int, roleIDs []string, positionRules []PositionAccessRule, studentRules []StudentAccessRule, groupIDs []string) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -194,21 +208,39 @@ func UpdateApplication(db *sqlx.DB, id, name, description, slug, targetURL, icon
 		return err
 	}
 
-	if len(posIDs) > 0 {
-		stmt, err := tx.Prepare(`INSERT INTO application_position_access (application_id, position_id) VALUES (?, ?)`)
+	if len(positionRules) > 0 {
+		stmt, err := tx.Prepare(`INSERT INTO application_position_access (application_id, position_id, major_id, study_program_id) VALUES (?, ?, ?, ?)`)
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 
-		for _, pid := range posIDs {
-			_, err := stmt.Exec(id, pid)
+		for _, rule := range positionRules {
+			_, err := stmt.Exec(id, rule.PositionID, rule.MajorID, rule.StudyProgramID)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	_, err = tx.Exec(`DELETE FROM application_student_access WHERE application_id=?`, id)
+	if err != nil {
+		return err
+	}
+
+	if err := insertStudentAccessRules(tx, id, studentRules); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM application_group_access WHERE application_id=?`, id)
+	if err != nil {
+		return err
+	}
+
+	if err := insertGroupAccess(tx, id, groupIDs); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return err
 	}
@@ -229,45 +261,104 @@ func DeleteApplication(db *sqlx.DB, id string) error {
 // FindApplicationBySlug mengambil satu aplikasi berdasarkan slug-nya.
 func FindApplicationBySlug(db *sqlx.DB, slug string) (Application, error) {
 	var app Application
-	query := `SELECT id, name, description, slug, target_url, icon_url, category_id FROM applications WHERE slug = ?`
+	query := `SELECT id, name, description, slug, target_url, icon_url, category_id, token_delivery, token_post_url, revocation_url, advisee_api FROM applications WHERE slug = ?`
 	err := db.Get(&app, query, slug)
 	return app, err
 }
 
-// FindAccessibleApps mengambil aplikasi yang dapat diakses berdasarkan role dan posisi.
-func FindAccessibleApps(db *sqlx.DB, roleName string, positionIDs []int, categoryID int) ([]Application, error) {
-    
+// FindAccessibleApps mengambil aplikasi pada sebuah kategori yang dapat diakses user (lihat evaluateApps).
+func FindAccessibleApps(db *sqlx.DB, user *FullUser, categoryID int, ctx AccessContext) ([]Application, error) {
+    return accessibleApps(db, user, ctx, `a.category_id = ?`, categoryID)
+}
 
-    if len(positionIDs) == 0 {
-        positionIDs = []int{0}
-    }
+// FindAllAccessibleApps mengambil semua aplikasi lintas kategori yang dapat diakses user.
+func FindAllAccessibleApps(db *sqlx.DB, user *FullUser, ctx AccessContext) ([]Application, error) {
+    return accessibleApps(db, user, ctx, `1 = 1`)
+}
 
-    query := `
-    -- Bagian 1: Ambil Apps berdasarkan ROLE (Admin/Mhs/Dosen)
-    SELECT a.id, a.name, a.description, a.slug, a.target_url, a.icon_url
-    FROM applications a
-    JOIN application_role_access ara ON a.id = ara.application_id
-    JOIN roles r ON ara.role_id = r.id
-    WHERE r.role_name = ? AND a.category_id = ?
-    
-    UNION
-
-    -- Bagian 2: Ambil Apps berdasarkan POSITION (untuk Dosen)
-    SELECT a.id, a.name, a.description, a.slug, a.target_url, a.icon_url
-    FROM applications a
-    JOIN application_position_access apa ON a.id = apa.application_id
-    WHERE apa.position_id IN (?) AND a.category_id = ?
-    `
-
-    query, args, err := sqlx.In(query, roleName, categoryID, positionIDs, categoryID)
+func accessibleApps(db *sqlx.DB, user *FullUser, ctx AccessContext, where string, args ...interface{}) ([]Application, error) {
+    evaluated, _, err := evaluateApps(db, user, ctx, where, args...)
     if err != nil {
         return nil, err
     }
-    
-    query = db.Rebind(query)
-    
+
     var apps []Application
-    err = db.Select(&apps, query, args...)
-    
-    return apps, err
+    for _, app := range evaluated {
+        if app.Decision.Allowed {
+            apps = append(apps, app.Application)
+        }
+    }
+    return apps, nil
+}
+
+// GetUserEffectiveAccess mengevaluasi akses user ke semua aplikasi beserta alasannya,
+// dengan jalur evaluasi yang sama seperti FindAccessibleApps.
+func GetUserEffectiveAccess(db *sqlx.DB, user *FullUser, ctx AccessContext) ([]AppAccess, error) {
+    evaluated, _, err := evaluateApps(db, user, ctx, `1 = 1`)
+    return evaluated, err
+}
+
+// CanAccessApp mengecek hak akses dan ketersediaan satu aplikasi bagi user dengan aturan yang sama seperti FindAccessibleApps.
+func CanAccessApp(db *sqlx.DB, user *FullUser, appID int, ctx AccessContext) (AppAccess, error) {
+    evaluated, _, err := evaluateApps(db, user, ctx, `a.id = ?`, appID)
+    if err != nil {
+        return AppAccess{}, err
+    }
+    if len(evaluated) == 0 {
+        return AppAccess{}, sql.ErrNoRows
+    }
+    return evaluated[0], nil
+}
+
+// accessSubjectIDs mengembalikan lecturer_id dan student_id user untuk aturan akses.
+// Akses berbasis jabatan hanya untuk dosen, akses berbasis atribut hanya untuk mahasiswa.
+func accessSubjectIDs(user *FullUser) (int, int) {
+    roleName := user.Roles[0].Name
+
+    lecturerID, studentID := 0, 0
+    if roleName == "dosen" && user.Lecturer != nil {
+        lecturerID = user.Lecturer.ID
+    }
+    if roleName == "mahasiswa" && user.Student != nil {
+        studentID = user.Student.ID
+    }
+    return lecturerID, studentID
+}
+
+// appRuleColumns membangun kolom aturan akses aplikasi (alias a) untuk user, satu kolom per jalur akses:
+// role, jabatan dosen yang sedang berlaku (beserta lingkup unitnya), atribut mahasiswa (prodi, jurusan,
+// angkatan, status akademik), dan nama grup yang memberi akses. Kolom terpisah dipakai untuk menjelaskan alasan akses.
+func appRuleColumns(user *FullUser) (string, []interface{}) {
+    lecturerID, studentID := accessSubjectIDs(user)
+
+    cols := `
+        -- Bagian 1: akses berdasarkan ROLE (Admin/Mhs/Dosen)
+        EXISTS (
+            SELECT 1 FROM application_role_access ara
+            JOIN roles r ON ara.role_id = r.id
+            WHERE ara.application_id = a.id AND r.role_name = ?
+        ) AS rule_role,
+
+        -- Bagian 2: akses berdasarkan POSITION (untuk Dosen), sesuai lingkup unit aturannya
+        EXISTS (
+            SELECT 1 FROM application_position_access apa
+            WHERE apa.application_id = a.id AND ` + positionRuleCondition + `
+        ) AS rule_position,
+
+        -- Bagian 3: akses berdasarkan atribut MAHASISWA
+        EXISTS (
+            SELECT 1 FROM application_student_access asa
+            WHERE asa.application_id = a.id AND ` + studentRuleCondition + `
+        ) AS rule_student,
+
+        -- Bagian 4: akses berdasarkan keanggotaan GRUP (statis maupun dinamis)
+        (
+            SELECT GROUP_CONCAT(g.name ORDER BY g.name SEPARATOR ', ')
+            FROM application_group_access aga
+            JOIN user_groups g ON aga.group_id = g.id
+            JOIN users u ON u.id = ?
+            WHERE aga.application_id = a.id AND ` + groupMemberCondition + `
+        ) AS rule_groups`
+
+    return cols, []interface{}{user.Roles[0].Name, lecturerID, studentID, user.ID}
 }
@@ -0,0 +1,376 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrIncompletePositionRule dikembalikan jika aturan dibatasi jurusan/prodi tetapi unitnya belum dipilih.
+var ErrIncompletePositionRule = errors.New("unit aturan akses jabatan belum dipilih")
+
+// ErrIncompleteStudentRule dikembalikan jika aturan mahasiswa dibatasi unit tanpa unit, atau status akademiknya tidak dikenal.
+var ErrIncompleteStudentRule = errors.New("aturan akses mahasiswa tidak lengkap")
+
+// PositionAccessRule adalah aturan akses aplikasi berbasis jabatan. Jika MajorID/StudyProgramID diisi,
+// hanya pemegang jabatan pada jurusan/prodi tersebut yang mendapat akses.
+type PositionAccessRule struct {
+	ID             int            `db:"id"`
+	PositionID     int            `db:"position_id"`
+	PositionName   string         `db:"position_name"`
+	MajorID        sql.NullInt64  `db:"major_id"`
+	StudyProgramID sql.NullInt64  `db:"study_program_id"`
+	ScopeName      sql.NullString `db:"scope_name"`
+}
+
+// Scope mengembalikan jenis lingkup aturan: 'major', 'prodi', atau 'none'.
+func (p PositionAccessRule) Scope() string {
+	if p.MajorID.Valid {
+		return "major"
+	}
+	if p.StudyProgramID.Valid {
+		return "prodi"
+	}
+	return "none"
+}
+
+// Label menampilkan nama jabatan beserta unitnya, mis. "Kaprodi (Informatika)".
+func (p PositionAccessRule) Label() string {
+	if p.ScopeName.Valid {
+		return p.PositionName + " (" + p.ScopeName.String + ")"
+	}
+	return p.PositionName
+}
+
+// positionRuleCondition mencocokkan aturan apa (alias application_position_access) dengan jabatan
+// dosen yang sedang berlaku (parameter: lecturer_id). Aturan jurusan juga berlaku untuk jabatan pada prodi
+// di bawah jurusan tersebut.
+const positionRuleCondition = `EXISTS (
+		SELECT 1 FROM lecturer_positions lp
+		LEFT JOIN study_programs lp_sp ON lp.study_program_id = lp_sp.id
+		WHERE lp.lecturer_id = ? AND lp.position_id = apa.position_id
+		AND (apa.major_id IS NULL OR lp.major_id = apa.major_id OR lp_sp.major_id = apa.major_id)
+		AND (apa.study_program_id IS NULL OR lp.study_program_id = apa.study_program_id)
+		AND ` + activePositionCondition + `
+	)`
+
+// GetPositionAccessRules mengambil aturan akses jabatan sebuah aplikasi beserta nama unitnya.
+func GetPositionAccessRules(db *sqlx.DB, appID string) ([]PositionAccessRule, error) {
+	query := `
+		SELECT apa.id, apa.position_id, p.position_name, apa.major_id, apa.study_program_id,
+			COALESCE(m.major_name, sp.study_program_name) AS scope_name
+		FROM application_position_access apa
+		JOIN positions p ON apa.position_id = p.id
+		LEFT JOIN majors m ON apa.major_id = m.id
+		LEFT JOIN study_programs sp ON apa.study_program_id = sp.id
+		WHERE apa.application_id = ?
+		ORDER BY p.position_name ASC, scope_name ASC`
+
+	var rules []PositionAccessRule
+	err := db.Select(&rules, query, appID)
+	return rules, err
+}
+
+// ParsePositionAccessRules membaca aturan akses jabatan dari form (format sama dengan positions_json pada form user).
+func ParsePositionAccessRules(jsonStr string) ([]PositionAccessRule, error) {
+	if jsonStr == "" || jsonStr == "[]" {
+		return nil, nil
+	}
+
+	var raw []PositionFormJSON
+	if err := json.Unmarshal([]byte(jsonStr), &raw); err != nil {
+		return nil, err
+	}
+
+	var result []PositionAccessRule
+	for _, p := range raw {
+		if p.PositionID == 0 {
+			continue
+		}
+
+		// Aturan berlingkup tanpa unit ditolak agar tidak diam-diam berlaku untuk semua unit
+		rule := PositionAccessRule{PositionID: p.PositionID}
+		switch p.Scope {
+		case "major":
+			if p.MajorID <= 0 {
+				return nil, ErrIncompletePositionRule
+			}
+			rule.MajorID = sql.NullInt64{Int64: int64(p.MajorID), Valid: true}
+		case "prodi":
+			if p.ProdiID <= 0 {
+				return nil, ErrIncompletePositionRule
+			}
+			rule.StudyProgramID = sql.NullInt64{Int64: int64(p.ProdiID), Valid: true}
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// PositionAccessRulesJSON menyiapkan aturan untuk diisi ulang pada form edit aplikasi.
+func PositionAccessRulesJSON(rules []PositionAccessRule) string {
+	raw := []PositionFormJSON{}
+	for _, r := range rules {
+		item := PositionFormJSON{PositionID: r.PositionID, Scope: r.Scope()}
+		if r.MajorID.Valid {
+			item.MajorID = int(r.MajorID.Int64)
+		}
+		if r.StudyProgramID.Valid {
+			item.ProdiID = int(r.StudyProgramID.Int64)
+		}
+		raw = append(raw, item)
+	}
+	b, _ := json.Marshal(raw)
+	return string(b)
+}
+
+// StudentAccessRule adalah aturan akses aplikasi untuk mahasiswa. Setiap kriteria yang diisi harus cocok;
+// kriteria kosong berarti tidak dibatasi.
+type StudentAccessRule struct {
+	ID             int            `db:"id"`
+	MajorID        sql.NullInt64  `db:"major_id"`
+	StudyProgramID sql.NullInt64  `db:"study_program_id"`
+	CohortYear     sql.NullInt64  `db:"cohort_year"`
+	AcademicStatus sql.NullString `db:"academic_status"`
+	UnitName       sql.NullString `db:"unit_name"`
+}
+
+// StudentRuleFormJSON adalah format aturan akses mahasiswa pada form aplikasi.
+type StudentRuleFormJSON struct {
+	Scope          string `json:"scope"` // major, prodi, none
+	MajorID        int    `json:"major_id"`
+	ProdiID        int    `json:"prodi_id"`
+	CohortYear     int    `json:"cohort_year"`
+	AcademicStatus string `json:"academic_status"`
+}
+
+// Label menampilkan ringkasan aturan, mis. "Mahasiswa Informatika, Angkatan 2024, Aktif".
+func (s StudentAccessRule) Label() string {
+	label := "Mahasiswa"
+	if s.UnitName.Valid {
+		label += " " + s.UnitName.String
+	}
+	if s.CohortYear.Valid {
+		label += ", Angkatan " + strconv.FormatInt(s.CohortYear.Int64, 10)
+	}
+	if s.AcademicStatus.Valid {
+		label += ", " + AcademicStatusLabel(s.AcademicStatus.String)
+	}
+	return label
+}
+
+// studentRuleCondition mencocokkan aturan asa (alias application_student_access) dengan data mahasiswa
+// (parameter: student id). Aturan jurusan berlaku untuk semua prodi di bawah jurusan tersebut.
+const studentRuleCondition = `EXISTS (
+		SELECT 1 FROM students st
+		LEFT JOIN study_programs st_sp ON st.study_program_id = st_sp.id
+		WHERE st.id = ?
+		AND (asa.major_id IS NULL OR st_sp.major_id = asa.major_id)
+		AND (asa.study_program_id IS NULL OR st.study_program_id = asa.study_program_id)
+		AND (asa.cohort_year IS NULL OR st.cohort_year = asa.cohort_year)
+		AND (asa.academic_status IS NULL OR st.academic_status = asa.academic_status)
+	)`
+
+// GetStudentAccessRules mengambil aturan akses mahasiswa sebuah aplikasi beserta nama unitnya.
+func GetStudentAccessRules(db *sqlx.DB, appID string) ([]StudentAccessRule, error) {
+	query := `
+		SELECT asa.id, asa.major_id, asa.study_program_id, asa.cohort_year, asa.academic_status,
+			COALESCE(m.major_name, sp.study_program_name) AS unit_name
+		FROM application_student_access asa
+		LEFT JOIN majors m ON asa.major_id = m.id
+		LEFT JOIN study_programs sp ON asa.study_program_id = sp.id
+		WHERE asa.application_id = ?
+		ORDER BY asa.id ASC`
+
+	var rules []StudentAccessRule
+	err := db.Select(&rules, query, appID)
+	return rules, err
+}
+
+// ParseStudentAccessRules membaca aturan akses mahasiswa dari form aplikasi.
+func ParseStudentAccessRules(jsonStr string) ([]StudentAccessRule, error) {
+	if jsonStr == "" || jsonStr == "[]" {
+		return nil, nil
+	}
+
+	var raw []StudentRuleFormJSON
+	if err := json.Unmarshal([]byte(jsonStr), &raw); err != nil {
+		return nil, err
+	}
+
+	var result []StudentAccessRule
+	for _, s := range raw {
+		var rule StudentAccessRule
+		switch s.Scope {
+		case "major":
+			if s.MajorID <= 0 {
+				return nil, ErrIncompleteStudentRule
+			}
+			rule.MajorID = sql.NullInt64{Int64: int64(s.MajorID), Valid: true}
+		case "prodi":
+			if s.ProdiID <= 0 {
+				return nil, ErrIncompleteStudentRule
+			}
+			rule.StudyProgramID = sql.NullInt64{Int64: int64(s.ProdiID), Valid: true}
+		}
+		if s.CohortYear > 0 {
+			rule.CohortYear = sql.NullInt64{Int64: int64(s.CohortYear), Valid: true}
+		}
+		if s.AcademicStatus != "" {
+			if !IsValidAcademicStatus(s.AcademicStatus) {
+				return nil, ErrIncompleteStudentRule
+			}
+			rule.AcademicStatus = sql.NullString{String: s.AcademicStatus, Valid: true}
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// StudentAccessRulesJSON menyiapkan aturan untuk diisi ulang pada form edit aplikasi.
+func StudentAccessRulesJSON(rules []StudentAccessRule) string {
+	raw := []StudentRuleFormJSON{}
+	for _, r := range rules {
+		item := StudentRuleFormJSON{Scope: "none", AcademicStatus: r.AcademicStatus.String}
+		if r.MajorID.Valid {
+			item.Scope = "major"
+			item.MajorID = int(r.MajorID.Int64)
+		} else if r.StudyProgramID.Valid {
+			item.Scope = "prodi"
+			item.ProdiID = int(r.StudyProgramID.Int64)
+		}
+		if r.CohortYear.Valid {
+			item.CohortYear = int(r.CohortYear.Int64)
+		}
+		raw = append(raw, item)
+	}
+	b, _ := json.Marshal(raw)
+	return string(b)
+}
+
+func insertStudentAccessRules(tx *sql.Tx, appID interface{}, rules []StudentAccessRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO application_student_access (application_id, major_id, study_program_id, cohort_year, academic_status) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, rule := range rules {
+		if _, err := stmt.Exec(appID, rule.MajorID, rule.StudyProgramID, rule.CohortYear, rule.AcademicStatus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AccessMatrixRow adalah satu baris matriks akses: aturan role dan jabatan sebuah aplikasi,
+// ditambah jumlah aturan lain yang ikut menentukan akses.
+type AccessMatrixRow struct {
+	App           Application
+	Roles         map[int]bool
+	Positions     map[int][]string
+	StudentRules  int
+	GroupRules    int
+	PolicyCount   int
+	OverrideCount int
+}
+
+// AccessMatrix adalah matriks aplikasi × role/jabatan untuk halaman admin.
+type AccessMatrix struct {
+	Roles     []Role
+	Positions []Position
+	Rows      []AccessMatrixRow
+}
+
+// GetAccessMatrix menyusun matriks akses semua aplikasi dari application_role_access dan
+// application_position_access. Sel jabatan berisi lingkup unit aturannya ("Semua unit" jika tidak dibatasi).
+func GetAccessMatrix(db *sqlx.DB) (AccessMatrix, error) {
+	var matrix AccessMatrix
+	var err error
+
+	if matrix.Roles, err = GetAllRoles(db); err != nil {
+		return matrix, err
+	}
+	if matrix.Positions, err = GetAllPositions(db); err != nil {
+		return matrix, err
+	}
+
+	var counts []struct {
+		Application
+		StudentRules  int `db:"student_rules"`
+		GroupRules    int `db:"group_rules"`
+		PolicyCount   int `db:"policy_count"`
+		OverrideCount int `db:"override_count"`
+	}
+	err = db.Select(&counts, `
+		SELECT a.id, a.name, a.slug, COALESCE(c.name, '-') AS category_name,
+			(SELECT COUNT(*) FROM application_student_access asa WHERE asa.application_id = a.id) AS student_rules,
+			(SELECT COUNT(*) FROM application_group_access aga WHERE aga.application_id = a.id) AS group_rules,
+			(SELECT COUNT(*) FROM application_policies pol WHERE pol.application_id = a.id AND pol.is_active = 1) AS policy_count,
+			(SELECT COUNT(*) FROM application_user_overrides auo WHERE auo.application_id = a.id AND `+activeOverrideCondition+`) AS override_count
+		FROM applications a
+		LEFT JOIN categories c ON a.category_id = c.id
+		ORDER BY category_name ASC, a.name ASC`)
+	if err != nil {
+		return matrix, err
+	}
+
+	var roleRules []struct {
+		ApplicationID int `db:"application_id"`
+		RoleID        int `db:"role_id"`
+	}
+	if err = db.Select(&roleRules, `SELECT application_id, role_id FROM application_role_access`); err != nil {
+		return matrix, err
+	}
+
+	var positionRules []struct {
+		ApplicationID int            `db:"application_id"`
+		PositionID    int            `db:"position_id"`
+		ScopeName     sql.NullString `db:"scope_name"`
+	}
+	err = db.Select(&positionRules, `
+		SELECT apa.application_id, apa.position_id, COALESCE(m.major_name, sp.study_program_name) AS scope_name
+		FROM application_position_access apa
+		LEFT JOIN majors m ON apa.major_id = m.id
+		LEFT JOIN study_programs sp ON apa.study_program_id = sp.id
+		ORDER BY scope_name ASC`)
+	if err != nil {
+		return matrix, err
+	}
+
+	index := make(map[int]int)
+	for i, c := range counts {
+		index[c.ID] = i
+		matrix.Rows = append(matrix.Rows, AccessMatrixRow{
+			App:           c.Application,
+			Roles:         make(map[int]bool),
+			Positions:     make(map[int][]string),
+			StudentRules:  c.StudentRules,
+			GroupRules:    c.GroupRules,
+			PolicyCount:   c.PolicyCount,
+			OverrideCount: c.OverrideCount,
+		})
+	}
+	for _, rule := range roleRules {
+		if i, ok := index[rule.ApplicationID]; ok {
+			matrix.Rows[i].Roles[rule.RoleID] = true
+		}
+	}
+	for _, rule := range positionRules {
+		if i, ok := index[rule.ApplicationID]; ok {
+			scope := "Semua unit"
+			if rule.ScopeName.Valid {
+				scope = rule.ScopeName.String
+			}
+			matrix.Rows[i].Positions[rule.PositionID] = append(matrix.Rows[i].Positions[rule.PositionID], scope)
+		}
+	}
+	return matrix, nil
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrInvalidAvailabilityWindow dikembalikan jika rentang jadwal ketersediaan tidak valid.
+var ErrInvalidAvailabilityWindow = errors.New("jadwal ketersediaan aplikasi tidak valid")
+
+// AvailabilityWindow adalah rentang waktu aplikasi dapat dibuka, mis. masa KRS. Jadwal dengan RoleID
+// menggantikan jadwal umum bagi role tersebut; jadwal role tanpa tanggal berarti selalu buka untuk role itu.
+// OpensAt inklusif dan ClosesAt eksklusif; tanggal kosong berarti tidak dibatasi pada sisi tersebut.
+type AvailabilityWindow struct {
+	ID            int            `db:"id"`
+	ApplicationID int            `db:"application_id"`
+	RoleID        sql.NullInt64  `db:"role_id"`
+	RoleName      sql.NullString `db:"role_name"`
+	Label         string         `db:"label"`
+	OpensAt       sql.NullTime   `db:"opens_at"`
+	ClosesAt      sql.NullTime   `db:"closes_at"`
+}
+
+// AppAvailability adalah status ketersediaan aplikasi bagi user pada suatu waktu.
+type AppAvailability struct {
+	Open     bool
+	Label    string
+	NextOpen *time.Time
+	ClosesAt *time.Time
+}
+
+// Message menjelaskan status aplikasi yang sedang ditutup.
+func (a AppAvailability) Message() string {
+	if a.Open {
+		return ""
+	}
+	if a.NextOpen != nil {
+		return "Aplikasi ini dibuka kembali pada " + a.NextOpen.Format("02 Jan 2006 15:04") + "."
+	}
+	return "Aplikasi ini sedang tidak dibuka."
+}
+
+// ValidateAvailabilityWindow memastikan label terisi dan jadwal umum memiliki minimal satu tanggal.
+func ValidateAvailabilityWindow(w AvailabilityWindow) error {
+	if w.Label == "" {
+		return ErrInvalidAvailabilityWindow
+	}
+	if !w.RoleID.Valid && !w.OpensAt.Valid && !w.ClosesAt.Valid {
+		return ErrInvalidAvailabilityWindow
+	}
+	if w.OpensAt.Valid && w.ClosesAt.Valid && !w.ClosesAt.Time.After(w.OpensAt.Time) {
+		return ErrInvalidAvailabilityWindow
+	}
+	return nil
+}
+
+const availabilitySelect = `
+	SELECT aw.id, aw.application_id, aw.role_id, r.role_name, aw.label, aw.opens_at, aw.closes_at
+	FROM application_availability_windows aw
+	LEFT JOIN roles r ON aw.role_id = r.id`
+
+// GetAppAvailabilityWindows mengambil jadwal ketersediaan sebuah aplikasi.
+func GetAppAvailabilityWindows(db *sqlx.DB, appID int) ([]AvailabilityWindow, error) {
+	var windows []AvailabilityWindow
+	err := db.Select(&windows, availabilitySelect+` WHERE aw.application_id = ? ORDER BY aw.role_id IS NOT NULL, r.role_name, aw.opens_at`, appID)
+	localizeWindows(windows)
+	return windows, err
+}
+
+func FindAvailabilityWindowByID(db *sqlx.DB, id int) (AvailabilityWindow, error) {
+	var window AvailabilityWindow
+	err := db.Get(&window, availabilitySelect+` WHERE aw.id = ?`, id)
+	return window, err
+}
+
+func CreateAvailabilityWindow(db *sqlx.DB, w AvailabilityWindow) error {
+	_, err := db.Exec(`INSERT INTO application_availability_windows (application_id, role_id, label, opens_at, closes_at)
+		VALUES (?, ?, ?, ?, ?)`, w.ApplicationID, w.RoleID, w.Label, wallClockArg(w.OpensAt), wallClockArg(w.ClosesAt))
+	return err
+}
+
+func DeleteAvailabilityWindow(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`DELETE FROM application_availability_windows WHERE id = ?`, id)
+	return err
+}
+
+// loadAppAvailability menghitung ketersediaan aplikasi untuk role user pada waktu now.
+// Aplikasi tanpa jadwal yang berlaku selalu buka.
+func loadAppAvailability(db *sqlx.DB, appIDs []int, roleID int, now time.Time) (map[int]AppAvailability, error) {
+	result := make(map[int]AppAvailability)
+	if len(appIDs) == 0 {
+		return result, nil
+	}
+
+	query, args, err := sqlx.In(availabilitySelect+` WHERE aw.application_id IN (?) AND (aw.role_id IS NULL OR aw.role_id = ?)`, appIDs, roleID)
+	if err != nil {
+		return nil, err
+	}
+	var windows []AvailabilityWindow
+	if err := db.Select(&windows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	localizeWindows(windows)
+
+	general := make(map[int][]AvailabilityWindow)
+	byRole := make(map[int][]AvailabilityWindow)
+	for _, w := range windows {
+		if w.RoleID.Valid {
+			byRole[w.ApplicationID] = append(byRole[w.ApplicationID], w)
+		} else {
+			general[w.ApplicationID] = append(general[w.ApplicationID], w)
+		}
+	}
+
+	for _, id := range appIDs {
+		applicable := general[id]
+		if len(byRole[id]) > 0 {
+			applicable = byRole[id]
+		}
+		result[id] = availabilityAt(applicable, now)
+	}
+	return result, nil
+}
+
+func availabilityAt(windows []AvailabilityWindow, now time.Time) AppAvailability {
+	if len(windows) == 0 {
+		return AppAvailability{Open: true}
+	}
+
+	var status AppAvailability
+	var nextLabel string
+	for _, w := range windows {
+		started := !w.OpensAt.Valid || !now.Before(w.OpensAt.Time)
+		ended := w.ClosesAt.Valid && !now.Before(w.ClosesAt.Time)
+
+		switch {
+		case started && !ended:
+			// Jika beberapa jadwal sedang buka, tampilkan yang tutup paling akhir
+			if !status.Open || (status.ClosesAt != nil && (!w.ClosesAt.Valid || w.ClosesAt.Time.After(*status.ClosesAt))) {
+				status.Open, status.Label, status.ClosesAt = true, w.Label, nil
+				if w.ClosesAt.Valid {
+					closesAt := w.ClosesAt.Time
+					status.ClosesAt = &closesAt
+				}
+			}
+		case !started:
+			if status.NextOpen == nil || w.OpensAt.Time.Before(*status.NextOpen) {
+				opensAt := w.OpensAt.Time
+				status.NextOpen, nextLabel = &opensAt, w.Label
+			}
+		}
+	}
+
+	if status.Open {
+		status.NextOpen = nil
+	} else {
+		status.Label = nextLabel
+	}
+	return status
+}
+
+// localizeWindows mengubah waktu hasil scan (dibaca driver sebagai UTC) menjadi waktu lokal server
+// dengan jam dinding yang sama, sesuai cara jadwal diinput admin.
+func localizeWindows(windows []AvailabilityWindow) {
+	for i := range windows {
+		windows[i].OpensAt.Time = wallClock(windows[i].OpensAt.Time)
+		windows[i].ClosesAt.Time = wallClock(windows[i].ClosesAt.Time)
+	}
+}
+
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+}
+
+func wallClockArg(t sql.NullTime) interface{} {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrInvalidDeepLinkPrefix dikembalikan jika awalan path deep link tidak valid.
+	ErrInvalidDeepLinkPrefix = errors.New("awalan path deep link tidak valid")
+	// ErrDeepLinkNotAllowed dikembalikan jika path tujuan tidak valid atau tidak termasuk awalan yang diizinkan aplikasi.
+	ErrDeepLinkNotAllowed = errors.New("path tujuan tidak diizinkan untuk aplikasi ini")
+)
+
+// DeepLinkPrefix adalah awalan path aplikasi yang boleh dituju langsung melalui /redirect.
+type DeepLinkPrefix struct {
+	ID            int    `db:"id"`
+	ApplicationID int    `db:"application_id"`
+	Prefix        string `db:"prefix"`
+}
+
+// NormalizeDeepLinkPrefix memvalidasi awalan path: harus diawali "/", tanpa query, fragment, atau segmen relatif.
+func NormalizeDeepLinkPrefix(prefix string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if len(prefix) > 255 || strings.ContainsAny(prefix, "?#") || !isSafePath(prefix) {
+		return "", ErrInvalidDeepLinkPrefix
+	}
+	return prefix, nil
+}
+
+// GetDeepLinkPrefixes mengambil awalan path deep link sebuah aplikasi.
+func GetDeepLinkPrefixes(db *sqlx.DB, appID int) ([]DeepLinkPrefix, error) {
+	var prefixes []DeepLinkPrefix
+	err := db.Select(&prefixes, `SELECT id, application_id, prefix FROM application_deep_link_prefixes WHERE application_id = ? ORDER BY prefix ASC`, appID)
+	return prefixes, err
+}
+
+func FindDeepLinkPrefixByID(db *sqlx.DB, id int) (DeepLinkPrefix, error) {
+	var prefix DeepLinkPrefix
+	err := db.Get(&prefix, `SELECT id, application_id, prefix FROM application_deep_link_prefixes WHERE id = ?`, id)
+	return prefix, err
+}
+
+// CreateDeepLinkPrefix menambahkan awalan path. Awalan yang sudah ada diabaikan.
+func CreateDeepLinkPrefix(db *sqlx.DB, appID int, prefix string) error {
+	_, err := db.Exec(`INSERT IGNORE INTO application_deep_link_prefixes (application_id, prefix) VALUES (?, ?)`, appID, prefix)
+	return err
+}
+
+func DeleteDeepLinkPrefix(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`DELETE FROM application_deep_link_prefixes WHERE id = ?`, id)
+	return err
+}
+
+// ResolveDeepLink memvalidasi path tujuan (boleh berisi query) terhadap awalan yang diizinkan aplikasi
+// dan mengembalikan bentuk yang sudah dinormalisasi. Path harus relatif terhadap aplikasi: URL absolut,
+// "//host", backslash, dan segmen "." / ".." ditolak agar tidak bisa dipakai sebagai open redirect.
+func ResolveDeepLink(db *sqlx.DB, appID int, raw string) (string, error) {
+	u, err := parseDeepLink(raw)
+	if err != nil {
+		return "", err
+	}
+
+	prefixes, err := GetDeepLinkPrefixes(db, appID)
+	if err != nil {
+		return "", err
+	}
+	return matchDeepLink(u, prefixes)
+}
+
+// parseDeepLink memeriksa bentuk path tujuan sebelum dicocokkan dengan awalan aplikasi.
+func parseDeepLink(raw string) (*url.URL, error) {
+	lower := strings.ToLower(raw)
+	if len(raw) > 2048 || !isSafePath(raw) || strings.Contains(lower, "%2e") || strings.Contains(lower, "%2f") || strings.Contains(lower, "%5c") {
+		return nil, ErrDeepLinkNotAllowed
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" || !isSafePath(u.Path) {
+		return nil, ErrDeepLinkNotAllowed
+	}
+	return u, nil
+}
+
+// matchDeepLink mengembalikan path yang sudah dinormalisasi jika termasuk salah satu awalan yang diizinkan.
+func matchDeepLink(u *url.URL, prefixes []DeepLinkPrefix) (string, error) {
+	for _, p := range prefixes {
+		if pathHasPrefix(u.Path, p.Prefix) {
+			resolved := u.EscapedPath()
+			if u.RawQuery != "" {
+				resolved += "?" + u.RawQuery
+			}
+			if u.Fragment != "" {
+				resolved += "#" + u.EscapedFragment()
+			}
+			return resolved, nil
+		}
+	}
+	return "", ErrDeepLinkNotAllowed
+}
+
+// isSafePath memastikan path diawali satu "/" (bukan "//"), tanpa backslash, karakter kontrol, atau segmen "." / "..".
+func isSafePath(p string) bool {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.ContainsAny(p, "\\") {
+		return false
+	}
+	for _, c := range p {
+		if c < 0x20 || c == 0x7f {
+			return false
+		}
+	}
+	path := p
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// pathHasPrefix mencocokkan awalan per segmen: awalan "/krs" cocok dengan "/krs" dan "/krs/123", tetapi tidak dengan "/krs-lama".
+func pathHasPrefix(path, prefix string) bool {
+	if prefix == "/" || path == prefix {
+		return true
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
@@ -0,0 +1,93 @@
+package models
+
+import "testing"
+
+func TestResolveDeepLinkPath(t *testing.T) {
+	prefixes := []DeepLinkPrefix{{Prefix: "/krs"}, {Prefix: "/pengumuman/"}}
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"awalan persis", "/krs", "/krs", true},
+		{"di bawah awalan", "/krs/123", "/krs/123", true},
+		{"query dipertahankan", "/krs/123?tab=nilai", "/krs/123?tab=nilai", true},
+		{"fragment dipertahankan", "/pengumuman/5#lampiran", "/pengumuman/5#lampiran", true},
+		{"spasi di-escape", "/krs/a b", "/krs/a%20b", true},
+		{"awalan tanpa batas segmen", "/krs-lama", "", false},
+		{"awalan dengan slash tidak cocok tanpa slash", "/pengumuman", "", false},
+		{"tidak ada awalan", "/nilai", "", false},
+		{"url absolut", "https://evil.test/krs", "", false},
+		{"protocol relative", "//evil.test/krs", "", false},
+		{"backslash", "/krs\\..\\admin", "", false},
+		{"segmen naik", "/krs/../admin", "", false},
+		{"segmen titik", "/krs/./1", "", false},
+		{"titik ter-encode", "/krs/%2e%2e/admin", "", false},
+		{"slash ter-encode", "/krs%2f..%2fadmin", "", false},
+		{"backslash ter-encode", "/krs%5cadmin", "", false},
+		{"karakter kontrol", "/krs/\n1", "", false},
+		{"tanpa slash awal", "krs/1", "", false},
+		{"kosong", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := parseDeepLink(tt.raw)
+			got := ""
+			if err == nil {
+				got, err = matchDeepLink(u, prefixes)
+			}
+			if tt.ok && (err != nil || got != tt.want) {
+				t.Fatalf("deep link %q = %q, %v; want %q", tt.raw, got, err, tt.want)
+			}
+			if !tt.ok && err != ErrDeepLinkNotAllowed {
+				t.Fatalf("deep link %q = %q, %v; want ErrDeepLinkNotAllowed", tt.raw, got, err)
+			}
+		})
+	}
+}
+
+func TestPathHasPrefix(t *testing.T) {
+	tests := []struct {
+		path, prefix string
+		want         bool
+	}{
+		{"/krs", "/krs", true},
+		{"/krs/1", "/krs", true},
+		{"/krs/1", "/krs/", true},
+		{"/krs-lama", "/krs", false},
+		{"/kr", "/krs", false},
+		{"/apa/saja", "/", true},
+	}
+
+	for _, tt := range tests {
+		if got := pathHasPrefix(tt.path, tt.prefix); got != tt.want {
+			t.Errorf("pathHasPrefix(%q, %q) = %v; want %v", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDeepLinkPrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+		ok     bool
+	}{
+		{" /krs/ ", "/krs/", true},
+		{"/", "/", true},
+		{"krs", "", false},
+		{"//krs", "", false},
+		{"/krs?x=1", "", false},
+		{"/krs#a", "", false},
+		{"/krs/../admin", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeDeepLinkPrefix(tt.prefix)
+		if tt.ok != (err == nil) || got != tt.want {
+			t.Errorf("NormalizeDeepLinkPrefix(%q) = %q, %v; want %q (ok=%v)", tt.prefix, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HealthCheckTarget adalah aplikasi yang diperiksa oleh health checker.
+type HealthCheckTarget struct {
+	ID           int    `db:"id"`
+	Name         string `db:"name"`
+	URL          string `db:"url"`
+	HealthStatus string `db:"health_status"`
+}
+
+// HealthCheck adalah satu hasil pemeriksaan status aplikasi.
+type HealthCheck struct {
+	ID            int64          `db:"id"`
+	ApplicationID int            `db:"application_id"`
+	Status        string         `db:"status"`
+	HTTPStatus    sql.NullInt64  `db:"http_status"`
+	ResponseMS    sql.NullInt64  `db:"response_ms"`
+	Error         sql.NullString `db:"error"`
+	CheckedAt     time.Time      `db:"checked_at"`
+}
+
+// AppHealth adalah pengaturan pemeliharaan dan status terakhir aplikasi untuk halaman admin.
+type AppHealth struct {
+	HealthURL          sql.NullString  `db:"health_url"`
+	HealthStatus       string          `db:"health_status"`
+	HealthCheckedAt    sql.NullTime    `db:"health_checked_at"`
+	MaintenanceMode    bool            `db:"maintenance_mode"`
+	MaintenanceMessage sql.NullString  `db:"maintenance_message"`
+	Uptime24h          sql.NullFloat64 `db:"uptime_24h"`
+}
+
+// GetHealthCheckTargets mengambil semua aplikasi beserta URL yang diperiksa (health_url atau target_url).
+func GetHealthCheckTargets(db *sqlx.DB) ([]HealthCheckTarget, error) {
+	var targets []HealthCheckTarget
+	err := db.Select(&targets, `
+		SELECT id, name, COALESCE(NULLIF(health_url, ''), target_url) AS url, health_status
+		FROM applications ORDER BY id ASC`)
+	return targets, err
+}
+
+// RecordHealthCheck menyimpan hasil pemeriksaan ke riwayat dan memperbarui status terakhir aplikasi.
+func RecordHealthCheck(db *sqlx.DB, check HealthCheck) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO application_health_checks (application_id, status, http_status, response_ms, error)
+		VALUES (?, ?, ?, ?, ?)`, check.ApplicationID, check.Status, check.HTTPStatus, check.ResponseMS, check.Error)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE applications SET health_status = ?, health_checked_at = NOW() WHERE id = ?`, check.Status, check.ApplicationID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAppHealth mengambil pengaturan pemeliharaan, status terakhir, dan persentase uptime 24 jam sebuah aplikasi.
+func GetAppHealth(db *sqlx.DB, appID int) (AppHealth, error) {
+	var health AppHealth
+	err := db.Get(&health, `
+		SELECT a.health_url, a.health_status, a.health_checked_at, a.maintenance_mode, a.maintenance_message,
+			(SELECT AVG(hc.status = 'up') * 100 FROM application_health_checks hc
+				WHERE hc.application_id = a.id AND hc.checked_at >= NOW() - INTERVAL 1 DAY) AS uptime_24h
+		FROM applications a WHERE a.id = ?`, appID)
+	return health, err
+}
+
+// GetAppHealthHistory mengambil riwayat pemeriksaan terbaru sebuah aplikasi.
+func GetAppHealthHistory(db *sqlx.DB, appID, limit int) ([]HealthCheck, error) {
+	var checks []HealthCheck
+	err := db.Select(&checks, `
+		SELECT id, application_id, status, http_status, response_ms, error, checked_at
+		FROM application_health_checks WHERE application_id = ?
+		ORDER BY checked_at DESC, id DESC LIMIT ?`, appID, limit)
+	return checks, err
+}
+
+// UpdateAppMaintenance menyimpan URL health check serta status dan pesan pemeliharaan aplikasi.
+func UpdateAppMaintenance(db *sqlx.DB, appID int, healthURL string, maintenance bool, message string) error {
+	_, err := db.Exec(`UPDATE applications SET health_url = NULLIF(?, ''), maintenance_mode = ?, maintenance_message = NULLIF(?, '')
+		WHERE id = ?`, healthURL, maintenance, message, appID)
+	return err
+}
+
+// PurgeHealthChecks menghapus riwayat pemeriksaan yang lebih tua dari jumlah hari yang ditentukan.
+func PurgeHealthChecks(db *sqlx.DB, days int) (int64, error) {
+	res, err := db.Exec(`DELETE FROM application_health_checks WHERE checked_at < NOW() - INTERVAL ? DAY`, days)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
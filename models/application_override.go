@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AppUserOverride adalah pengecualian akses aplikasi untuk satu user. Effect 'allow' memberi akses
+// di luar aturan role/jabatan/mahasiswa/grup, sedangkan 'deny' mencabut akses dan selalu menang.
+// ExpiresAt (inklusif) diisi untuk akses sementara hasil pengajuan akses yang disetujui.
+type AppUserOverride struct {
+	ID            int            `db:"id"`
+	ApplicationID int            `db:"application_id"`
+	AppName       string         `db:"app_name"`
+	UserID        int            `db:"user_id"`
+	UserName      string         `db:"user_name"`
+	UserEmail     string         `db:"user_email"`
+	Effect        string         `db:"effect"`
+	Reason        sql.NullString `db:"reason"`
+	ExpiresAt     sql.NullTime   `db:"expires_at"`
+	CreatedByName sql.NullString `db:"created_by_name"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
+
+// activeOverrideCondition memastikan pengecualian auo belum kedaluwarsa.
+const activeOverrideCondition = `(auo.expires_at IS NULL OR auo.expires_at >= CURDATE())`
+
+// IsValidOverrideEffect memastikan effect pengecualian yang dikirim dari form dikenal.
+func IsValidOverrideEffect(effect string) bool {
+	return effect == "allow" || effect == "deny"
+}
+
+const appOverrideColumns = `
+	SELECT auo.id, auo.application_id, a.name AS app_name, auo.user_id, u.name AS user_name, u.email AS user_email,
+		auo.effect, auo.reason, auo.expires_at, cb.name AS created_by_name, auo.created_at
+	FROM application_user_overrides auo
+	JOIN applications a ON auo.application_id = a.id
+	JOIN users u ON auo.user_id = u.id
+	LEFT JOIN users cb ON auo.created_by = cb.id`
+
+// GetUserAppOverrides mengambil semua pengecualian akses aplikasi milik seorang user.
+func GetUserAppOverrides(db *sqlx.DB, userID int) ([]AppUserOverride, error) {
+	var overrides []AppUserOverride
+	err := db.Select(&overrides, appOverrideColumns+` WHERE auo.user_id = ? ORDER BY a.name ASC`, userID)
+	return overrides, err
+}
+
+// GetAppUserOverrides mengambil semua pengecualian akses user pada sebuah aplikasi.
+func GetAppUserOverrides(db *sqlx.DB, appID int) ([]AppUserOverride, error) {
+	var overrides []AppUserOverride
+	err := db.Select(&overrides, appOverrideColumns+` WHERE auo.application_id = ? ORDER BY auo.effect DESC, u.name ASC`, appID)
+	return overrides, err
+}
+
+// FindAppOverrideByID mengambil satu pengecualian akses berdasarkan id.
+func FindAppOverrideByID(db *sqlx.DB, id int) (AppUserOverride, error) {
+	var override AppUserOverride
+	err := db.Get(&override, appOverrideColumns+` WHERE auo.id = ?`, id)
+	return override, err
+}
+
+// SetAppUserOverride menyimpan pengecualian akses permanen user pada aplikasi. Satu user hanya punya satu
+// pengecualian per aplikasi, sehingga entri yang sudah ada (termasuk akses sementara) akan ditimpa.
+func SetAppUserOverride(db *sqlx.DB, appID, userID int, effect, reason string, adminID int) error {
+	_, err := db.Exec(`
+		INSERT INTO application_user_overrides (application_id, user_id, effect, reason, created_by)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?)
+		ON DUPLICATE KEY UPDATE effect = VALUES(effect), reason = VALUES(reason), expires_at = NULL,
+			created_by = VALUES(created_by), created_at = CURRENT_TIMESTAMP`,
+		appID, userID, effect, reason, adminID)
+	return err
+}
+
+// FindAppUserOverride mengambil pengecualian user pada sebuah aplikasi, nil jika tidak ada.
+func FindAppUserOverride(db *sqlx.DB, appID, userID int) (*AppUserOverride, error) {
+	var override AppUserOverride
+	err := db.Get(&override, appOverrideColumns+` WHERE auo.application_id = ? AND auo.user_id = ?`, appID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+// DeleteAppUserOverride menghapus pengecualian akses.
+func DeleteAppUserOverride(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`DELETE FROM application_user_overrides WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Cara pengiriman token portal ke aplikasi.
+const (
+	// TokenDeliveryQuery mengirim token sebagai ?token= pada redirect ke target_url.
+	TokenDeliveryQuery = "query"
+	// TokenDeliveryFormPost mengirim token melalui form POST otomatis (seperti response_mode=form_post OIDC),
+	// sehingga token tidak muncul di URL, header Referer, maupun log reverse proxy.
+	TokenDeliveryFormPost = "form_post"
+)
+
+// TokenEndpoint mengembalikan URL yang menerima token: token_post_url untuk form_post jika diisi, selain itu target_url.
+func (a Application) TokenEndpoint() string {
+	if a.TokenDelivery == TokenDeliveryFormPost && a.TokenPostURL.Valid && a.TokenPostURL.String != "" {
+		return a.TokenPostURL.String
+	}
+	return a.TargetURL
+}
+
+// UpdateAppTokenDelivery menyimpan cara pengiriman token, endpoint POST, endpoint notifikasi pencabutan,
+// dan izin aplikasi memanggil API mahasiswa bimbingan.
+func UpdateAppTokenDelivery(db *sqlx.DB, appID int, delivery, postURL, revocationURL string, adviseeAPI bool) error {
+	_, err := db.Exec(`UPDATE applications SET token_delivery = ?, token_post_url = NULLIF(?, ''), revocation_url = NULLIF(?, ''), advisee_api = ? WHERE id = ?`,
+		delivery, postURL, revocationURL, adviseeAPI, appID)
+	return err
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// claimLabels adalah nama klaim token yang ditampilkan kepada user pada layar persetujuan.
+var claimLabels = map[string]string{
+	"name":             "Nama lengkap",
+	"email":            "Alamat email",
+	"avatar":           "Foto profil",
+	"role":             "Role",
+	"student_id":       "ID mahasiswa",
+	"academic_status":  "Status akademik",
+	"study_program_id": "Program studi",
+	"major_id":         "Jurusan",
+	"cohort_year":      "Angkatan",
+	"advisor_id":       "Dosen wali",
+	"lecturer_id":      "ID dosen",
+	"advisee_count":    "Jumlah mahasiswa wali",
+	"positions":        "Jabatan",
+	"groups":           "Grup pengguna",
+	ClaimAdviseeAPI:    "Daftar mahasiswa bimbingan",
+}
+
+// ClaimAdviseeAPI adalah kunci persetujuan untuk token API mahasiswa bimbingan. Token API hanya diterima
+// selama persetujuan user untuk aplikasi tersebut masih memuat kunci ini.
+const ClaimAdviseeAPI = "advisee_api"
+
+// ClaimLabel mengembalikan nama klaim yang mudah dibaca user.
+func ClaimLabel(key string) string {
+	if label, ok := claimLabels[key]; ok {
+		return label
+	}
+	return key
+}
+
+// AppConsent adalah persetujuan user atas data yang dikirim ke sebuah aplikasi.
+type AppConsent struct {
+	UserID         int            `db:"user_id"`
+	ApplicationID  int            `db:"application_id"`
+	AppName        string         `db:"app_name"`
+	AppSlug        string         `db:"app_slug"`
+	IconURL        sql.NullString `db:"icon_url"`
+	Claims         string         `db:"claims"`
+	GrantedAt      time.Time      `db:"granted_at"`
+	LastLaunchedAt sql.NullTime   `db:"last_launched_at"`
+}
+
+// Includes menandakan kunci klaim termasuk dalam persetujuan.
+func (c AppConsent) Includes(key string) bool {
+	for _, k := range strings.Split(c.Claims, ",") {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimLabels mengembalikan nama klaim yang disetujui.
+func (c AppConsent) ClaimLabels() []string {
+	var labels []string
+	for _, key := range strings.Split(c.Claims, ",") {
+		if key != "" {
+			labels = append(labels, ClaimLabel(key))
+		}
+	}
+	return labels
+}
+
+const consentSelect = `
+	SELECT uc.user_id, uc.application_id, a.name AS app_name, a.slug AS app_slug, a.icon_url, uc.claims, uc.granted_at,
+		(SELECT MAX(l.launched_at) FROM application_launches l
+			WHERE l.user_id = uc.user_id AND l.application_id = uc.application_id) AS last_launched_at
+	FROM user_app_consents uc
+	JOIN applications a ON uc.application_id = a.id`
+
+// GetAppConsent mengambil persetujuan user untuk sebuah aplikasi. Mengembalikan nil jika belum pernah disetujui.
+func GetAppConsent(db *sqlx.DB, userID, appID int) (*AppConsent, error) {
+	var consent AppConsent
+	err := db.Get(&consent, consentSelect+` WHERE uc.user_id = ? AND uc.application_id = ?`, userID, appID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// GetUserConsents mengambil semua aplikasi yang sudah disetujui user, terbaru lebih dulu.
+func GetUserConsents(db *sqlx.DB, userID int) ([]AppConsent, error) {
+	var consents []AppConsent
+	err := db.Select(&consents, consentSelect+` WHERE uc.user_id = ? ORDER BY uc.granted_at DESC`, userID)
+	return consents, err
+}
+
+// GrantAppConsent menyimpan persetujuan user atas daftar klaim (terurut, dipisah koma), menggantikan persetujuan sebelumnya.
+func GrantAppConsent(db *sqlx.DB, userID, appID int, claims string) error {
+	_, err := db.Exec(`
+		INSERT INTO user_app_consents (user_id, application_id, claims, granted_at) VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE claims = VALUES(claims), granted_at = NOW()`, userID, appID, claims)
+	return err
+}
+
+// RevokeAppConsent mencabut persetujuan user untuk sebuah aplikasi. Mengembalikan false jika tidak ada persetujuan.
+func RevokeAppConsent(db *sqlx.DB, userID, appID int) (bool, error) {
+	res, err := db.Exec(`DELETE FROM user_app_consents WHERE user_id = ? AND application_id = ?`, userID, appID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
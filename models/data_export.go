@@ -0,0 +1,312 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Status permintaan export data pribadi.
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport adalah permintaan export data pribadi user beserta file hasilnya.
+type DataExport struct {
+	ID          int            `db:"id"`
+	UserID      int            `db:"user_id"`
+	Token       string         `db:"token"`
+	Status      string         `db:"status"`
+	FilePath    sql.NullString `db:"file_path"`
+	FileSize    sql.NullInt64  `db:"file_size"`
+	RequestedAt time.Time      `db:"requested_at"`
+	CompletedAt sql.NullTime   `db:"completed_at"`
+	ExpiresAt   sql.NullTime   `db:"expires_at"`
+}
+
+// Downloadable menandakan file export sudah siap dan tautannya belum kedaluwarsa.
+func (e DataExport) Downloadable() bool {
+	return e.Status == DataExportReady && e.ExpiresAt.Valid && time.Now().Before(e.ExpiresAt.Time)
+}
+
+const dataExportColumns = `id, user_id, token, status, file_path, file_size, requested_at, completed_at, expires_at`
+
+// CreateDataExport mencatat permintaan export baru dengan status pending.
+func CreateDataExport(db *sqlx.DB, userID int, token string) (int64, error) {
+	res, err := db.Exec(`INSERT INTO user_data_exports (user_id, token, status, requested_at) VALUES (?, ?, 'pending', NOW())`, userID, token)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FindLatestDataExport mengambil permintaan export terakhir user. Mengembalikan nil jika belum pernah meminta.
+func FindLatestDataExport(db *sqlx.DB, userID int) (*DataExport, error) {
+	var export DataExport
+	err := db.Get(&export, `SELECT `+dataExportColumns+` FROM user_data_exports WHERE user_id = ? ORDER BY id DESC LIMIT 1`, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindDataExportByToken mengambil permintaan export milik user berdasarkan token tautan unduhan.
+func FindDataExportByToken(db *sqlx.DB, userID int, token string) (DataExport, error) {
+	var export DataExport
+	err := db.Get(&export, `SELECT `+dataExportColumns+` FROM user_data_exports WHERE user_id = ? AND token = ?`, userID, token)
+	return export, err
+}
+
+// MarkDataExportReady menyimpan lokasi file hasil export dan batas waktu unduhannya.
+func MarkDataExportReady(db *sqlx.DB, id int, filePath string, size int64, expiresAt time.Time) error {
+	_, err := db.Exec(`
+		UPDATE user_data_exports SET status = 'ready', file_path = ?, file_size = ?, completed_at = NOW(), expires_at = ?
+		WHERE id = ?`, filePath, size, expiresAt, id)
+	return err
+}
+
+func MarkDataExportFailed(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`UPDATE user_data_exports SET status = 'failed', completed_at = NOW() WHERE id = ?`, id)
+	return err
+}
+
+// GetExpiredDataExports mengambil export yang tautannya sudah kedaluwarsa dan filenya masih tersimpan.
+func GetExpiredDataExports(db *sqlx.DB) ([]DataExport, error) {
+	var exports []DataExport
+	err := db.Select(&exports, `SELECT `+dataExportColumns+` FROM user_data_exports WHERE expires_at < NOW() AND file_path IS NOT NULL`)
+	return exports, err
+}
+
+// GetStaleDataExports mengambil permintaan yang masih pending lebih lama dari batas waktu,
+// mis. karena proses berhenti saat arsip sedang disusun.
+func GetStaleDataExports(db *sqlx.DB, olderThan time.Duration) ([]DataExport, error) {
+	var exports []DataExport
+	err := db.Select(&exports, `SELECT `+dataExportColumns+` FROM user_data_exports
+		WHERE status = 'pending' AND requested_at < NOW() - INTERVAL ? SECOND`, int(olderThan.Seconds()))
+	return exports, err
+}
+
+// ClearDataExportFile menandai file export sudah dihapus dari penyimpanan.
+func ClearDataExportFile(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`UPDATE user_data_exports SET file_path = NULL WHERE id = ?`, id)
+	return err
+}
+
+// PersonalData adalah seluruh data user yang disimpan portal, isi file export.
+type PersonalData struct {
+	ExportedAt        time.Time                  `json:"exported_at"`
+	Profile           PersonalProfile            `json:"profile"`
+	Roles             []string                   `json:"roles"`
+	Student           *PersonalStudent           `json:"student,omitempty"`
+	Lecturer          *PersonalLecturer          `json:"lecturer,omitempty"`
+	Positions         []PersonalPosition         `json:"positions"`
+	Groups            []string                   `json:"groups"`
+	Notifications     []PersonalNotification     `json:"notifications"`
+	PushSubscriptions []PersonalPushSubscription `json:"push_subscriptions"`
+	ConnectedApps     []PersonalConsent          `json:"connected_apps"`
+	LoginHistory      []PersonalLogin            `json:"login_history"`
+	LaunchHistory     []PersonalLaunch           `json:"app_launch_history"`
+}
+
+type PersonalProfile struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Status       string `json:"status"`
+	Address      string `json:"address,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	Avatar       string `json:"avatar,omitempty"`
+	GoogleAvatar string `json:"google_avatar,omitempty"`
+}
+
+type PersonalStudent struct {
+	NIM            string `json:"nim,omitempty"`
+	StudyProgramID int64  `json:"study_program_id,omitempty"`
+	MajorID        int64  `json:"major_id,omitempty"`
+	CohortYear     int64  `json:"cohort_year,omitempty"`
+	AcademicStatus string `json:"academic_status"`
+	AdvisorUserID  int64  `json:"advisor_user_id,omitempty"`
+}
+
+type PersonalLecturer struct {
+	NIP            string `json:"nip,omitempty"`
+	NUPTK          string `json:"nuptk,omitempty"`
+	StudyProgramID int64  `json:"study_program_id,omitempty"`
+}
+
+type PersonalPosition struct {
+	Name           string `json:"name"`
+	MajorID        int64  `json:"major_id,omitempty"`
+	StudyProgramID int64  `json:"study_program_id,omitempty"`
+	StartDate      string `json:"start_date,omitempty"`
+	EndDate        string `json:"end_date,omitempty"`
+}
+
+type PersonalNotification struct {
+	Application string       `json:"application" db:"app_name"`
+	Message     string       `json:"message" db:"message"`
+	UpdatedAt   sql.NullTime `json:"-" db:"updated_at"`
+	Time        string       `json:"time,omitempty" db:"-"`
+}
+
+type PersonalPushSubscription struct {
+	Endpoint  string       `json:"endpoint" db:"endpoint"`
+	CreatedAt sql.NullTime `json:"-" db:"created_at"`
+	Time      string       `json:"created_at,omitempty" db:"-"`
+}
+
+type PersonalConsent struct {
+	Application string    `json:"application" db:"app_name"`
+	Claims      string    `json:"claims" db:"claims"`
+	GrantedAt   time.Time `json:"granted_at" db:"granted_at"`
+}
+
+type PersonalLogin struct {
+	Role      string    `json:"role,omitempty" db:"role_name"`
+	IPAddress string    `json:"ip_address" db:"ip_address"`
+	UserAgent string    `json:"user_agent" db:"user_agent"`
+	LoggedIn  time.Time `json:"logged_in_at" db:"logged_in_at"`
+}
+
+type PersonalLaunch struct {
+	Application string    `json:"application" db:"app_name"`
+	Role        string    `json:"role,omitempty" db:"role_name"`
+	LaunchedAt  time.Time `json:"launched_at" db:"launched_at"`
+}
+
+// CollectPersonalData mengumpulkan seluruh data user untuk export, termasuk riwayat jabatan yang sudah berakhir.
+func CollectPersonalData(db *sqlx.DB, userID int) (*PersonalData, error) {
+	user, err := FindUserByID(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &PersonalData{
+		ExportedAt: time.Now(),
+		Profile: PersonalProfile{
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Status:       user.Status,
+			Address:      user.Address.String,
+			Phone:        user.Phone.String,
+			Avatar:       user.Avatar.String,
+			GoogleAvatar: user.GoogleAvatar.String,
+		},
+		Roles:             []string{},
+		Positions:         []PersonalPosition{},
+		Groups:            []string{},
+		Notifications:     []PersonalNotification{},
+		PushSubscriptions: []PersonalPushSubscription{},
+		ConnectedApps:     []PersonalConsent{},
+		LoginHistory:      []PersonalLogin{},
+		LaunchHistory:     []PersonalLaunch{},
+	}
+
+	for _, role := range user.Roles {
+		data.Roles = append(data.Roles, role.Name)
+	}
+
+	if user.Student != nil && user.Student.ID != 0 {
+		data.Student = &PersonalStudent{
+			NIM:            user.Student.NIM.String,
+			StudyProgramID: user.Student.StudyProgramID.Int64,
+			MajorID:        user.Student.MajorID.Int64,
+			CohortYear:     user.Student.CohortYear.Int64,
+			AcademicStatus: user.Student.AcademicStatus,
+			AdvisorUserID:  user.Student.AdvisorUserID.Int64,
+		}
+	}
+
+	if user.Lecturer != nil && user.Lecturer.ID != 0 {
+		data.Lecturer = &PersonalLecturer{
+			NIP:            user.Lecturer.NIP.String,
+			NUPTK:          user.Lecturer.NUPTK.String,
+			StudyProgramID: user.Lecturer.StudyProgramID.Int64,
+		}
+
+		history, err := GetLecturerPositionHistory(db, user.Lecturer.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range history {
+			position := PersonalPosition{Name: p.PositionName, MajorID: p.MajorID.Int64, StudyProgramID: p.StudyProgramID.Int64}
+			if p.StartDate != nil {
+				position.StartDate = *p.StartDate
+			}
+			if p.EndDate != nil {
+				position.EndDate = *p.EndDate
+			}
+			data.Positions = append(data.Positions, position)
+		}
+	}
+
+	groups, err := GetUserGroups(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		data.Groups = append(data.Groups, g.Name)
+	}
+
+	err = db.Select(&data.Notifications, `
+		SELECT a.name AS app_name, COALESCE(n.message, '') AS message, n.updated_at
+		FROM application_notifications n
+		JOIN applications a ON n.app_id = a.id
+		WHERE n.user_id = ? ORDER BY n.id ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range data.Notifications {
+		if n.UpdatedAt.Valid {
+			data.Notifications[i].Time = n.UpdatedAt.Time.Format(time.RFC3339)
+		}
+	}
+
+	err = db.Select(&data.PushSubscriptions, `SELECT endpoint, created_at FROM user_push_subscriptions WHERE user_id = ? ORDER BY id ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range data.PushSubscriptions {
+		if s.CreatedAt.Valid {
+			data.PushSubscriptions[i].Time = s.CreatedAt.Time.Format(time.RFC3339)
+		}
+	}
+
+	err = db.Select(&data.ConnectedApps, `
+		SELECT a.name AS app_name, uc.claims, uc.granted_at
+		FROM user_app_consents uc
+		JOIN applications a ON uc.application_id = a.id
+		WHERE uc.user_id = ? ORDER BY uc.granted_at ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Select(&data.LoginHistory, `
+		SELECT COALESCE(r.role_name, '') AS role_name, COALESCE(l.ip_address, '') AS ip_address,
+			COALESCE(l.user_agent, '') AS user_agent, l.logged_in_at
+		FROM user_logins l
+		LEFT JOIN roles r ON l.role_id = r.id
+		WHERE l.user_id = ? ORDER BY l.logged_in_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Select(&data.LaunchHistory, `
+		SELECT a.name AS app_name, COALESCE(r.role_name, '') AS role_name, l.launched_at
+		FROM application_launches l
+		JOIN applications a ON l.application_id = a.id
+		LEFT JOIN roles r ON l.role_id = r.id
+		WHERE l.user_id = ? ORDER BY l.launched_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
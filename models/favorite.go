@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// GetFavoriteAppIDs mengambil id aplikasi favorit user sesuai urutan saat ditambahkan.
+func GetFavoriteAppIDs(db *sqlx.DB, userID int) ([]int, error) {
+	ids := []int{}
+	err := db.Select(&ids, `SELECT application_id FROM user_favorite_apps WHERE user_id = ? ORDER BY created_at ASC, application_id ASC`, userID)
+	return ids, err
+}
+
+// AddFavoriteApp menyematkan aplikasi ke daftar favorit user. Aplikasi yang sudah favorit diabaikan.
+func AddFavoriteApp(db *sqlx.DB, userID, appID int) error {
+	_, err := db.Exec(`INSERT IGNORE INTO user_favorite_apps (user_id, application_id) VALUES (?, ?)`, userID, appID)
+	return err
+}
+
+func RemoveFavoriteApp(db *sqlx.DB, userID, appID int) error {
+	_, err := db.Exec(`DELETE FROM user_favorite_apps WHERE user_id = ? AND application_id = ?`, userID, appID)
+	return err
+}
+
+// GetRecentAppIDs mengambil id aplikasi yang terakhir dibuka user dari riwayat pembukaan, terbaru lebih dulu.
+func GetRecentAppIDs(db *sqlx.DB, userID, limit int) ([]int, error) {
+	ids := []int{}
+	err := db.Select(&ids, `
+		SELECT application_id FROM application_launches
+		WHERE user_id = ?
+		GROUP BY application_id
+		ORDER BY MAX(launched_at) DESC
+		LIMIT ?`, userID, limit)
+	return ids, err
+}
+
+// PickApps mengambil aplikasi dari daftar sesuai urutan ids. Id yang tidak ada di daftar
+// (mis. aplikasi yang tidak lagi dapat diakses) dilewati.
+func PickApps(apps []Application, ids []int) []Application {
+	byID := make(map[int]Application, len(apps))
+	for _, app := range apps {
+		byID[app.ID] = app
+	}
+
+	picked := []Application{}
+	for _, id := range ids {
+		if app, ok := byID[id]; ok {
+			picked = append(picked, app)
+		}
+	}
+	return picked
+}
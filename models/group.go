@@ -0,0 +1,358 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Group adalah kumpulan pengguna ad-hoc (panitia, lab riset, tim akreditasi) yang bisa diberi akses aplikasi.
+// Grup statis berisi anggota yang ditambahkan admin; anggota grup dinamis dihitung langsung dari GroupRule.
+type Group struct {
+	ID             int            `db:"id"`
+	Name           string         `db:"name"`
+	Slug           string         `db:"slug"`
+	Description    sql.NullString `db:"description"`
+	MembershipType string         `db:"membership_type"` // static, dynamic
+	MemberCount    int            `db:"member_count"`
+	GroupRule
+}
+
+// IsDynamic mengecek apakah keanggotaan grup dihitung dari aturan.
+func (g Group) IsDynamic() bool {
+	return g.MembershipType == "dynamic"
+}
+
+// GroupRule adalah aturan keanggotaan grup dinamis. Kriteria kosong berarti tidak dibatasi.
+// Untuk dosen, unit dicocokkan dengan homebase, atau dengan unit jabatan jika HasPosition diisi.
+type GroupRule struct {
+	Target         sql.NullString `db:"rule_target"` // mahasiswa, dosen
+	MajorID        sql.NullInt64  `db:"rule_major_id"`
+	StudyProgramID sql.NullInt64  `db:"rule_study_program_id"`
+	CohortYear     sql.NullInt64  `db:"rule_cohort_year"`
+	AcademicStatus sql.NullString `db:"rule_academic_status"`
+	HasPosition    bool           `db:"rule_has_position"`
+	PositionID     sql.NullInt64  `db:"rule_position_id"`   // Kosong = jabatan apa pun
+	UnitName       sql.NullString `db:"rule_unit_name"`     // Join result
+	PositionName   sql.NullString `db:"rule_position_name"` // Join result
+}
+
+// Label menampilkan ringkasan aturan, mis. "Mahasiswa Informatika, Angkatan 2024, Aktif".
+func (g GroupRule) Label() string {
+	unit := ""
+	if g.UnitName.Valid {
+		unit = " " + g.UnitName.String
+	}
+
+	if g.Target.String == "mahasiswa" {
+		label := "Mahasiswa" + unit
+		if g.CohortYear.Valid {
+			label += ", Angkatan " + strconv.FormatInt(g.CohortYear.Int64, 10)
+		}
+		if g.AcademicStatus.Valid {
+			label += ", " + AcademicStatusLabel(g.AcademicStatus.String)
+		}
+		return label
+	}
+
+	if !g.HasPosition {
+		if unit == "" {
+			return "Semua dosen aktif"
+		}
+		return "Dosen homebase" + unit
+	}
+	position := "jabatan apa pun"
+	if g.PositionName.Valid {
+		position = "jabatan " + g.PositionName.String
+	}
+	if unit == "" {
+		return "Dosen dengan " + position
+	}
+	return "Dosen dengan " + position + " di" + unit
+}
+
+// ErrIncompleteGroupRule dikembalikan jika aturan grup dinamis tidak lengkap atau tidak valid.
+var ErrIncompleteGroupRule = errors.New("aturan grup dinamis tidak lengkap")
+
+// ValidateGroupRule memastikan aturan grup dinamis memiliki sasaran yang valid dan kriterianya sesuai sasaran.
+func ValidateGroupRule(rule GroupRule) error {
+	switch rule.Target.String {
+	case "mahasiswa":
+		if rule.HasPosition || rule.PositionID.Valid {
+			return ErrIncompleteGroupRule
+		}
+		if rule.AcademicStatus.Valid && !IsValidAcademicStatus(rule.AcademicStatus.String) {
+			return ErrIncompleteGroupRule
+		}
+	case "dosen":
+		if rule.CohortYear.Valid || rule.AcademicStatus.Valid {
+			return ErrIncompleteGroupRule
+		}
+		if rule.PositionID.Valid && !rule.HasPosition {
+			return ErrIncompleteGroupRule
+		}
+	default:
+		return ErrIncompleteGroupRule
+	}
+	if rule.MajorID.Valid && rule.StudyProgramID.Valid {
+		return ErrIncompleteGroupRule
+	}
+	return nil
+}
+
+// groupMemberCondition mencocokkan grup g (alias user_groups, atau subquery dengan kolom yang sama) dengan
+// user u (alias users). Dipakai bersama oleh klaim token, akses aplikasi, jumlah anggota, dan preview anggota,
+// sehingga keanggotaan grup dinamis selalu mengikuti data users, students, lecturers, dan lecturer_positions terbaru.
+const groupMemberCondition = `(
+		(g.membership_type = 'static' AND EXISTS (
+			SELECT 1 FROM user_group_members gm WHERE gm.group_id = g.id AND gm.user_id = u.id
+		))
+		OR (g.membership_type = 'dynamic' AND u.status = 'aktif' AND (
+			(g.rule_target = 'mahasiswa' AND EXISTS (
+				SELECT 1 FROM students dg_s
+				LEFT JOIN study_programs dg_sp ON dg_s.study_program_id = dg_sp.id
+				WHERE dg_s.user_id = u.id
+				AND (g.rule_major_id IS NULL OR dg_sp.major_id = g.rule_major_id)
+				AND (g.rule_study_program_id IS NULL OR dg_s.study_program_id = g.rule_study_program_id)
+				AND (g.rule_cohort_year IS NULL OR dg_s.cohort_year = g.rule_cohort_year)
+				AND (g.rule_academic_status IS NULL OR dg_s.academic_status = g.rule_academic_status)
+			))
+			OR (g.rule_target = 'dosen' AND g.rule_has_position = 0 AND EXISTS (
+				SELECT 1 FROM lecturers dg_l
+				LEFT JOIN study_programs dg_sp ON dg_l.study_program_id = dg_sp.id
+				WHERE dg_l.user_id = u.id
+				AND (g.rule_major_id IS NULL OR dg_sp.major_id = g.rule_major_id)
+				AND (g.rule_study_program_id IS NULL OR dg_l.study_program_id = g.rule_study_program_id)
+			))
+			OR (g.rule_target = 'dosen' AND g.rule_has_position = 1 AND EXISTS (
+				SELECT 1 FROM lecturers dg_l
+				JOIN lecturer_positions lp ON lp.lecturer_id = dg_l.id
+				LEFT JOIN study_programs lp_sp ON lp.study_program_id = lp_sp.id
+				WHERE dg_l.user_id = u.id
+				AND (g.rule_position_id IS NULL OR lp.position_id = g.rule_position_id)
+				AND (g.rule_major_id IS NULL OR lp.major_id = g.rule_major_id OR lp_sp.major_id = g.rule_major_id)
+				AND (g.rule_study_program_id IS NULL OR lp.study_program_id = g.rule_study_program_id)
+				AND ` + activePositionCondition + `
+			))
+		))
+	)`
+
+const groupColumns = `g.id, g.name, g.slug, g.description, g.membership_type,
+		g.rule_target, g.rule_major_id, g.rule_study_program_id, g.rule_cohort_year, g.rule_academic_status,
+		g.rule_has_position, g.rule_position_id,
+		COALESCE(rm.major_name, rsp.study_program_name) AS rule_unit_name, rp.position_name AS rule_position_name`
+
+const groupRuleJoins = `
+		LEFT JOIN majors rm ON g.rule_major_id = rm.id
+		LEFT JOIN study_programs rsp ON g.rule_study_program_id = rsp.id
+		LEFT JOIN positions rp ON g.rule_position_id = rp.id`
+
+// GroupMember adalah anggota sebuah grup.
+type GroupMember struct {
+	UserID   int    `db:"user_id"`
+	Name     string `db:"name"`
+	Email    string `db:"email"`
+	RoleName string `db:"role_name"`
+	AddedAt  string `db:"added_at"`
+}
+
+var groupSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// IsValidGroupSlug mengecek slug grup: huruf kecil, angka, dan tanda hubung. Slug dikirim sebagai claim token.
+func IsValidGroupSlug(slug string) bool {
+	return groupSlugPattern.MatchString(slug)
+}
+
+func GetAllGroups(db *sqlx.DB) ([]Group, error) {
+	var data []Group
+	err := db.Select(&data, `
+		SELECT `+groupColumns+`,
+			(SELECT COUNT(*) FROM users u WHERE u.deleted_at IS NULL AND `+groupMemberCondition+`) AS member_count
+		FROM user_groups g`+groupRuleJoins+`
+		ORDER BY g.name ASC`)
+	return data, err
+}
+
+func FindGroupByID(db *sqlx.DB, id int) (*Group, error) {
+	var g Group
+	err := db.Get(&g, `SELECT `+groupColumns+`, 0 AS member_count FROM user_groups g`+groupRuleJoins+` WHERE g.id = ?`, id)
+	return &g, err
+}
+
+// groupRuleArgs mengembalikan nilai kolom aturan untuk disimpan. Grup statis tidak menyimpan aturan.
+func groupRuleArgs(membershipType string, rule GroupRule) []interface{} {
+	if membershipType != "dynamic" {
+		rule = GroupRule{}
+	}
+	return []interface{}{membershipType, rule.Target, rule.MajorID, rule.StudyProgramID, rule.CohortYear,
+		rule.AcademicStatus, rule.HasPosition, rule.PositionID}
+}
+
+func CreateGroup(db *sqlx.DB, name, slug, description, membershipType string, rule GroupRule) error {
+	args := append([]interface{}{name, slug, description}, groupRuleArgs(membershipType, rule)...)
+	_, err := db.Exec(`INSERT INTO user_groups (name, slug, description, membership_type, rule_target, rule_major_id,
+		rule_study_program_id, rule_cohort_year, rule_academic_status, rule_has_position, rule_position_id)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?)`, args...)
+	return err
+}
+
+// UpdateGroup memperbarui grup. Anggota statis tetap disimpan saat grup diubah menjadi dinamis,
+// tetapi diabaikan selama grup bersifat dinamis.
+func UpdateGroup(db *sqlx.DB, id int, name, slug, description, membershipType string, rule GroupRule) error {
+	args := append([]interface{}{name, slug, description}, groupRuleArgs(membershipType, rule)...)
+	args = append(args, id)
+	_, err := db.Exec(`UPDATE user_groups SET name = ?, slug = ?, description = NULLIF(?, ''), membership_type = ?,
+		rule_target = ?, rule_major_id = ?, rule_study_program_id = ?, rule_cohort_year = ?, rule_academic_status = ?,
+		rule_has_position = ?, rule_position_id = ? WHERE id = ?`, args...)
+	return err
+}
+
+// DeleteGroup menghapus grup beserta keanggotaan dan aturan akses aplikasinya (cascade).
+func DeleteGroup(db *sqlx.DB, id int) error {
+	res, err := db.Exec(`DELETE FROM user_groups WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func GetGroupMembers(db *sqlx.DB, groupID int) ([]GroupMember, error) {
+	var data []GroupMember
+	err := db.Select(&data, `
+		SELECT u.id AS user_id, u.name, u.email, COALESCE(r.role_name, '-') AS role_name,
+			DATE_FORMAT(m.added_at, '%Y-%m-%d') AS added_at
+		FROM user_group_members m
+		JOIN users u ON m.user_id = u.id AND u.deleted_at IS NULL
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		LEFT JOIN roles r ON ur.role_id = r.id
+		WHERE m.group_id = ?
+		ORDER BY u.name ASC`, groupID)
+	return data, err
+}
+
+// AddGroupMembersByEmail menambahkan anggota berdasarkan daftar email (satu per baris atau dipisah koma).
+// Mengembalikan jumlah anggota baru dan email yang tidak ditemukan.
+func AddGroupMembersByEmail(db *sqlx.DB, groupID int, emailList string, adminID int) (added int, notFound []string, err error) {
+	emails := strings.FieldsFunc(emailList, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == ';'
+	})
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			continue
+		}
+
+		var userID int
+		err = tx.Get(&userID, `SELECT id FROM users WHERE LOWER(email) = ? AND deleted_at IS NULL`, email)
+		if err == sql.ErrNoRows {
+			notFound = append(notFound, email)
+			err = nil
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+
+		res, err := tx.Exec(`INSERT IGNORE INTO user_group_members (group_id, user_id, added_by) VALUES (?, ?, ?)`, groupID, userID, adminID)
+		if err != nil {
+			return 0, nil, err
+		}
+		n, _ := res.RowsAffected()
+		added += int(n)
+	}
+
+	return added, notFound, tx.Commit()
+}
+
+func RemoveGroupMember(db *sqlx.DB, groupID, userID int) error {
+	_, err := db.Exec(`DELETE FROM user_group_members WHERE group_id = ? AND user_id = ?`, groupID, userID)
+	return err
+}
+
+// GetUserGroups mengambil grup yang diikuti seorang user, baik statis maupun dinamis.
+func GetUserGroups(db *sqlx.DB, userID int) ([]Group, error) {
+	var data []Group
+	err := db.Select(&data, `
+		SELECT `+groupColumns+`, 0 AS member_count
+		FROM user_groups g`+groupRuleJoins+`
+		JOIN users u ON u.id = ? AND u.deleted_at IS NULL
+		WHERE `+groupMemberCondition+`
+		ORDER BY g.name ASC`, userID)
+	return data, err
+}
+
+// GetApplicationGroups mengambil grup yang diberi akses ke sebuah aplikasi.
+func GetApplicationGroups(db *sqlx.DB, appID string) ([]Group, error) {
+	var data []Group
+	err := db.Select(&data, `
+		SELECT `+groupColumns+`, 0 AS member_count
+		FROM user_groups g`+groupRuleJoins+`
+		JOIN application_group_access aga ON aga.group_id = g.id
+		WHERE aga.application_id = ?
+		ORDER BY g.name ASC`, appID)
+	return data, err
+}
+
+// PreviewGroupMembers menghitung anggota aturan grup dinamis (yang belum tentu tersimpan) dan mengambil
+// maksimal limit anggota pertama. Kondisinya sama dengan yang dipakai untuk klaim dan akses aplikasi.
+func PreviewGroupMembers(db *sqlx.DB, rule GroupRule, limit int) (int, []GroupMember, error) {
+	ruleTable := `(SELECT 0 AS id, 'dynamic' AS membership_type, ? AS rule_target, ? AS rule_major_id,
+		? AS rule_study_program_id, ? AS rule_cohort_year, ? AS rule_academic_status, ? AS rule_has_position,
+		? AS rule_position_id) g`
+	ruleArgs := groupRuleArgs("dynamic", rule)[1:]
+
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM users u JOIN `+ruleTable+`
+		WHERE u.deleted_at IS NULL AND `+groupMemberCondition, ruleArgs...)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var members []GroupMember
+	err = db.Select(&members, `
+		SELECT u.id AS user_id, u.name, u.email, COALESCE(r.role_name, '-') AS role_name, '' AS added_at
+		FROM users u JOIN `+ruleTable+`
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		LEFT JOIN roles r ON ur.role_id = r.id
+		WHERE u.deleted_at IS NULL AND `+groupMemberCondition+`
+		ORDER BY u.name ASC
+		LIMIT ?`, append(ruleArgs, limit)...)
+	return count, members, err
+}
+
+func insertGroupAccess(tx *sql.Tx, appID interface{}, groupIDs []string) error {
+	if len(groupIDs) == 0 {
+		return nil
+	}
+	stmt, err := tx.Prepare(`INSERT INTO application_group_access (application_id, group_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, gid := range groupIDs {
+		if _, err := stmt.Exec(appID, gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ type Lecturer struct {
 	UserID  int            `db:"user_id"`
 	NIP     sql.NullString `db:"nip"`
 	NUPTK   sql.NullString `db:"nuptk"`
+	StudyProgramID sql.NullInt64 `db:"study_program_id"`
 }
 
 type LecturerPosition struct {
@@ -67,3 +68,107 @@ func GetLecturerPositionsByLecturerID(db *sqlx.DB, lecturerID int) ([]LecturerPo
 	return positions, nil
 }
 
+
+// activePositionCondition membatasi lecturer_positions (alias lp) pada jabatan yang sedang berlaku:
+// belum ditandai berakhir, jabatannya tidak berada di Trash, dan tanggal hari ini berada di antara start_date dan end_date.
+const activePositionCondition = `lp.ended_at IS NULL
+		AND lp.position_id IN (SELECT ap.id FROM positions ap WHERE ap.deleted_at IS NULL)
+		AND (lp.start_date IS NULL OR lp.start_date <= CURDATE())
+		AND (lp.end_date IS NULL OR lp.end_date >= CURDATE())`
+
+const lecturerPositionColumns = `lp.id, lp.lecturer_id, lp.position_id, p.position_name, lp.major_id, lp.study_program_id,
+		DATE_FORMAT(lp.start_date, '%Y-%m-%d') AS start_date, DATE_FORMAT(lp.end_date, '%Y-%m-%d') AS end_date`
+
+// GetActiveLecturerPositions mengambil jabatan dosen yang sedang berlaku saja.
+// Dipakai untuk hak akses aplikasi dan klaim token.
+func GetActiveLecturerPositions(db *sqlx.DB, lecturerID int) ([]LecturerPosition, error) {
+	query := `SELECT ` + lecturerPositionColumns + `
+		FROM lecturer_positions lp
+		JOIN positions p ON lp.position_id = p.id
+		WHERE lp.lecturer_id = ? AND ` + activePositionCondition + `
+		ORDER BY lp.id ASC`
+
+	var positions []LecturerPosition
+	err := db.Select(&positions, query, lecturerID)
+	return positions, err
+}
+
+// GetLecturerPositionHistory mengambil seluruh riwayat jabatan dosen, termasuk yang sudah berakhir.
+// Dipakai form edit user agar riwayat tidak hilang saat disimpan ulang.
+func GetLecturerPositionHistory(db *sqlx.DB, lecturerID int) ([]LecturerPosition, error) {
+	query := `SELECT ` + lecturerPositionColumns + `
+		FROM lecturer_positions lp
+		JOIN positions p ON lp.position_id = p.id
+		WHERE lp.lecturer_id = ?
+		ORDER BY lp.id ASC`
+
+	var positions []LecturerPosition
+	err := db.Select(&positions, query, lecturerID)
+	return positions, err
+}
+
+// ExpiringPosition adalah jabatan dosen yang akan atau sudah melewati end_date, untuk job pengingat.
+type ExpiringPosition struct {
+	ID           int            `db:"id"`
+	UserID       int            `db:"user_id"`
+	UserName     string         `db:"user_name"`
+	UserEmail    string         `db:"user_email"`
+	PositionName string         `db:"position_name"`
+	ScopeName    sql.NullString `db:"scope_name"`
+	EndDate      string         `db:"end_date"`
+}
+
+// Label menampilkan nama jabatan beserta unitnya.
+func (e ExpiringPosition) Label() string {
+	if e.ScopeName.Valid {
+		return e.PositionName + " (" + e.ScopeName.String + ")"
+	}
+	return e.PositionName
+}
+
+const expiringPositionQuery = `
+	SELECT lp.id, u.id AS user_id, u.name AS user_name, u.email AS user_email, p.position_name,
+		COALESCE(m.major_name, sp.study_program_name) AS scope_name,
+		DATE_FORMAT(lp.end_date, '%Y-%m-%d') AS end_date
+	FROM lecturer_positions lp
+	JOIN lecturers l ON lp.lecturer_id = l.id
+	JOIN users u ON l.user_id = u.id AND u.deleted_at IS NULL
+	JOIN positions p ON lp.position_id = p.id
+	LEFT JOIN majors m ON lp.major_id = m.id
+	LEFT JOIN study_programs sp ON lp.study_program_id = sp.id
+	WHERE lp.ended_at IS NULL AND lp.end_date IS NOT NULL`
+
+// GetPositionsExpiringWithin mengambil jabatan aktif yang berakhir dalam n hari ke depan dan belum diingatkan.
+func GetPositionsExpiringWithin(db *sqlx.DB, days int) ([]ExpiringPosition, error) {
+	query := expiringPositionQuery + `
+		AND lp.expiry_notified_at IS NULL
+		AND lp.end_date >= CURDATE() AND lp.end_date <= DATE_ADD(CURDATE(), INTERVAL ? DAY)
+		ORDER BY lp.end_date ASC`
+
+	var data []ExpiringPosition
+	err := db.Select(&data, query, days)
+	return data, err
+}
+
+// MarkPositionExpiryNotified menandai jabatan sudah diingatkan agar tidak dikirim ulang.
+func MarkPositionExpiryNotified(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`UPDATE lecturer_positions SET expiry_notified_at = NOW() WHERE id = ?`, id)
+	return err
+}
+
+// GetLapsedPositions mengambil jabatan yang end_date-nya sudah lewat tetapi belum ditandai berakhir.
+func GetLapsedPositions(db *sqlx.DB) ([]ExpiringPosition, error) {
+	query := expiringPositionQuery + `
+		AND lp.end_date < CURDATE()
+		ORDER BY lp.end_date ASC`
+
+	var data []ExpiringPosition
+	err := db.Select(&data, query)
+	return data, err
+}
+
+// MarkPositionEnded menandai jabatan telah berakhir.
+func MarkPositionEnded(db *sqlx.DB, id int) error {
+	_, err := db.Exec(`UPDATE lecturer_positions SET ended_at = NOW() WHERE id = ? AND ended_at IS NULL`, id)
+	return err
+}
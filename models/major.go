@@ -1,6 +1,10 @@
 package models
 
-import "github.com/jmoiron/sqlx"
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
 
 type Major struct {
 	ID         int    `db:"id"`
@@ -35,7 +39,14 @@ func UpdateMajor(db *sqlx.DB, id int, name string) error {
 }
 
 func DeleteMajor(db *sqlx.DB, id int) error {
-	query := `DELETE FROM majors WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
+	query := `UPDATE majors SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Kunci izin panel admin. Izin melekat pada role melalui tabel role_permissions.
+const (
+	PermUsersRead          = "users.read"
+	PermUsersStatus        = "users.status"
+	PermUsersWrite         = "users.write"
+	PermRegistrationsWrite = "registrations.write"
+	PermGroupsWrite        = "groups.write"
+	PermAppsRead           = "apps.read"
+	PermAppsWrite          = "apps.write"
+	PermAppAccessWrite     = "apps.access"
+	PermCategoriesWrite    = "categories.write"
+	PermMasterWrite        = "master.write"
+	PermStudyProgramsWrite = "study_programs.write"
+	PermRolesWrite         = "roles.write"
+	PermTrashWrite         = "trash.write"
+)
+
+// SuperAdminRole selalu memiliki seluruh izin, agar panel admin tidak bisa terkunci.
+const SuperAdminRole = "admin"
+
+type Permission struct {
+	Key   string
+	Label string
+	Group string
+}
+
+// Permissions adalah katalog izin yang bisa dipilih pada form role.
+var Permissions = []Permission{
+	{PermUsersRead, "Lihat & export data pengguna", "Pengguna"},
+	{PermUsersStatus, "Aktifkan / nonaktifkan pengguna", "Pengguna"},
+	{PermUsersWrite, "Tambah, ubah, hapus & import pengguna", "Pengguna"},
+	{PermRegistrationsWrite, "Verifikasi pendaftaran & aturan provisioning", "Pengguna"},
+	{PermGroupsWrite, "Kelola grup pengguna & anggotanya", "Pengguna"},
+	{PermAppsRead, "Lihat data aplikasi", "Aplikasi"},
+	{PermAppsWrite, "Tambah, ubah & hapus aplikasi", "Aplikasi"},
+	{PermAppAccessWrite, "Izinkan / blokir aplikasi per pengguna", "Aplikasi"},
+	{PermCategoriesWrite, "Kelola kategori aplikasi", "Aplikasi"},
+	{PermMasterWrite, "Kelola jurusan & jabatan", "Data Master"},
+	{PermStudyProgramsWrite, "Kelola program studi", "Data Master"},
+	{PermRolesWrite, "Kelola role, izin & lingkup admin", "Sistem"},
+	{PermTrashWrite, "Pulihkan & hapus permanen data di Trash", "Sistem"},
+}
+
+// DelegatedPermissions adalah izin yang dimiliki admin terdelegasi (lingkup jurusan/prodi).
+// Akses aplikasi didelegasikan hanya sebagai pengecualian per pengguna dalam lingkup; aturan akses
+// aplikasi (role, jabatan, mahasiswa, grup, kebijakan) berlaku lintas unit sehingga tetap butuh apps.write.
+var DelegatedPermissions = []string{PermUsersRead, PermUsersStatus, PermUsersWrite, PermStudyProgramsWrite, PermAppAccessWrite}
+
+// AdminAccess adalah hak akses panel admin milik user yang login.
+type AdminAccess struct {
+	Permissions map[string]bool
+	Scope       *AdminScope
+}
+
+// Can mengecek apakah user memiliki izin tertentu.
+func (a *AdminAccess) Can(perm string) bool {
+	return a.Permissions[perm]
+}
+
+func IsValidPermission(key string) bool {
+	for _, p := range Permissions {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func GetRolePermissions(db *sqlx.DB, roleID int) ([]string, error) {
+	var data []string
+	err := db.Select(&data, `SELECT permission FROM role_permissions WHERE role_id = ? ORDER BY permission ASC`, roleID)
+	return data, err
+}
+
+// GetAllRolePermissions mengambil izin seluruh role, dikelompokkan per role_id.
+func GetAllRolePermissions(db *sqlx.DB) (map[int][]string, error) {
+	var rows []struct {
+		RoleID     int    `db:"role_id"`
+		Permission string `db:"permission"`
+	}
+	if err := db.Select(&rows, `SELECT role_id, permission FROM role_permissions ORDER BY role_id, permission`); err != nil {
+		return nil, err
+	}
+
+	result := map[int][]string{}
+	for _, row := range rows {
+		result[row.RoleID] = append(result[row.RoleID], row.Permission)
+	}
+	return result, nil
+}
+
+// SetRolePermissions mengganti seluruh izin sebuah role.
+func SetRolePermissions(db *sqlx.DB, roleID int, perms []string) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM role_permissions WHERE role_id = ?`, roleID); err != nil {
+		return err
+	}
+
+	for _, perm := range perms {
+		if !IsValidPermission(perm) {
+			continue
+		}
+		if _, err = tx.Exec(`INSERT INTO role_permissions (role_id, permission) VALUES (?, ?)`, roleID, perm); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IsPrivilegedRole mengecek apakah role memberi akses panel admin (role admin atau memiliki izin).
+func IsPrivilegedRole(db *sqlx.DB, roleID int) (bool, error) {
+	var count int
+	err := db.Get(&count, `
+		SELECT (SELECT COUNT(*) FROM roles WHERE id = ? AND role_name = ?)
+			+ (SELECT COUNT(*) FROM role_permissions WHERE role_id = ?)`,
+		roleID, SuperAdminRole, roleID)
+	return count > 0, err
+}
+
+// GetUserIDsWithPermission mengambil id user aktif yang memiliki izin tertentu secara global
+// (role admin atau role dengan izin tersebut). Dipakai untuk mengirim notifikasi ke admin.
+func GetUserIDsWithPermission(db *sqlx.DB, perm string) ([]int, error) {
+	var ids []int
+	err := db.Select(&ids, `
+		SELECT DISTINCT u.id
+		FROM users u
+		JOIN user_roles ur ON u.id = ur.user_id
+		JOIN roles r ON ur.role_id = r.id
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id AND rp.permission = ?
+		WHERE u.deleted_at IS NULL AND u.status = 'aktif'
+		AND (r.role_name = ? OR rp.role_id IS NOT NULL)`,
+		perm, SuperAdminRole)
+	return ids, err
+}
+
+// LoadAdminAccess menyusun hak akses panel admin untuk user yang login.
+// Izin dari role berlaku global; jika role tidak memiliki izin, lingkup terdelegasi dari admin_scopes dipakai.
+// Mengembalikan nil jika user tidak memiliki akses panel admin sama sekali.
+func LoadAdminAccess(db *sqlx.DB, user *FullUser) (*AdminAccess, error) {
+	access := &AdminAccess{Permissions: map[string]bool{}}
+	role := user.Roles[0]
+
+	if role.Name == SuperAdminRole {
+		for _, p := range Permissions {
+			access.Permissions[p.Key] = true
+		}
+		access.Scope = &AdminScope{Global: true}
+		return access, nil
+	}
+
+	perms, err := GetRolePermissions(db, role.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) > 0 {
+		for _, p := range perms {
+			access.Permissions[p] = true
+		}
+		// Pengelola aplikasi juga boleh mengatur pengecualian akses per pengguna
+		if access.Permissions[PermAppsWrite] {
+			access.Permissions[PermAppAccessWrite] = true
+		}
+		access.Scope = &AdminScope{Global: true}
+		return access, nil
+	}
+
+	scope, err := loadDelegatedScope(db, user.ID)
+	if err != nil || scope == nil {
+		return nil, err
+	}
+	for _, p := range DelegatedPermissions {
+		access.Permissions[p] = true
+	}
+	access.Scope = scope
+	return access, nil
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/jmoiron/sqlx"
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
 
 type Position struct {
 	ID            int    `db:"id"`
@@ -33,7 +37,14 @@ func UpdatePosition(db *sqlx.DB, id int, name string) error {
 }
 
 func DeletePosition(db *sqlx.DB, id int) error {
-	query := `DELETE FROM positions WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
+	query := `UPDATE positions SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ProvisioningRule struct {
+	ID                   int            `db:"id"`
+	Name                 string         `db:"name"`
+	Domain               string         `db:"domain"`
+	EmailPattern         string         `db:"email_pattern"`
+	RoleID               int            `db:"role_id"`
+	RoleName             string         `db:"role_name"`
+	NIMPattern           sql.NullString `db:"nim_pattern"`
+	RequiresVerification bool           `db:"requires_verification"`
+	IsActive             bool           `db:"is_active"`
+	Priority             int            `db:"priority"`
+	CreatedAt            time.Time      `db:"created_at"`
+}
+
+type ProvisioningRuleForm struct {
+	ID                   int
+	Name                 string
+	Domain               string
+	EmailPattern         string
+	RoleID               int
+	NIMPattern           *string
+	RequiresVerification bool
+	IsActive             bool
+	Priority             int
+}
+
+const provisioningRuleSelect = `
+	SELECT pr.id, pr.name, pr.domain, pr.email_pattern, pr.role_id, r.role_name, pr.nim_pattern,
+		pr.requires_verification, pr.is_active, pr.priority, pr.created_at
+	FROM provisioning_rules pr
+	JOIN roles r ON pr.role_id = r.id
+`
+
+func GetAllProvisioningRules(db *sqlx.DB) ([]ProvisioningRule, error) {
+	var data []ProvisioningRule
+	err := db.Select(&data, provisioningRuleSelect+` ORDER BY pr.priority ASC, pr.id ASC`)
+	return data, err
+}
+
+func FindProvisioningRuleByID(db *sqlx.DB, id int) (*ProvisioningRule, error) {
+	var pr ProvisioningRule
+	err := db.Get(&pr, provisioningRuleSelect+` WHERE pr.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+func CreateProvisioningRule(db *sqlx.DB, form ProvisioningRuleForm) error {
+	query := `INSERT INTO provisioning_rules (name, domain, email_pattern, role_id, nim_pattern, requires_verification, is_active, priority, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())`
+	_, err := db.Exec(query, form.Name, form.Domain, form.EmailPattern, form.RoleID, form.NIMPattern,
+		form.RequiresVerification, form.IsActive, form.Priority)
+	return err
+}
+
+func UpdateProvisioningRule(db *sqlx.DB, form ProvisioningRuleForm) error {
+	query := `UPDATE provisioning_rules SET name = ?, domain = ?, email_pattern = ?, role_id = ?, nim_pattern = ?,
+		requires_verification = ?, is_active = ?, priority = ?, updated_at = NOW() WHERE id = ?`
+	res, err := db.Exec(query, form.Name, form.Domain, form.EmailPattern, form.RoleID, form.NIMPattern,
+		form.RequiresVerification, form.IsActive, form.Priority, form.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func DeleteProvisioningRule(db *sqlx.DB, id int) error {
+	res, err := db.Exec(`DELETE FROM provisioning_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MatchProvisioningRule mencari aturan aktif pertama (berdasarkan prioritas) yang cocok dengan email.
+// Aturan lama untuk role dosen diabaikan karena akun dosen tanpa NIP/NUPTK tidak valid.
+// Mengembalikan nil jika tidak ada aturan yang cocok.
+func MatchProvisioningRule(db *sqlx.DB, email string) (*ProvisioningRule, error) {
+	var rules []ProvisioningRule
+	err := db.Select(&rules, provisioningRuleSelect+` WHERE pr.is_active = 1 AND r.deleted_at IS NULL AND r.role_name <> 'dosen'
+		ORDER BY pr.priority ASC, pr.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if rules[i].Matches(email) {
+			return &rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Matches mengecek domain dan pola regex email. Pola harus cocok dengan seluruh alamat email, bukan sebagian.
+// Aturan dengan pola NIM hanya cocok jika NIM berhasil diekstrak.
+func (pr *ProvisioningRule) Matches(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.HasSuffix(email, "@"+strings.ToLower(pr.Domain)) {
+		return false
+	}
+
+	re, err := regexp.Compile(`^(?:` + pr.EmailPattern + `)$`)
+	if err != nil || !re.MatchString(email) {
+		return false
+	}
+
+	if pr.NIMPattern.Valid && pr.NIMPattern.String != "" {
+		return pr.ExtractNIM(email) != ""
+	}
+	return true
+}
+
+// ExtractNIM mengambil NIM dari local part email memakai nim_pattern.
+// Jika pola memiliki grup, grup pertama yang dipakai; jika tidak, seluruh kecocokan.
+func (pr *ProvisioningRule) ExtractNIM(email string) string {
+	if !pr.NIMPattern.Valid || pr.NIMPattern.String == "" {
+		return ""
+	}
+
+	re, err := regexp.Compile(pr.NIMPattern.String)
+	if err != nil {
+		return ""
+	}
+
+	local := email
+	if at := strings.Index(email, "@"); at >= 0 {
+		local = email[:at]
+	}
+
+	m := re.FindStringSubmatch(local)
+	if m == nil {
+		return ""
+	}
+	if len(m) > 1 {
+		return m[1]
+	}
+	return m[0]
+}
+
+// ToUserForm menyusun data user baru dari aturan provisioning.
+func (pr *ProvisioningRule) ToUserForm(name, email string) UserForm {
+	status := "aktif"
+	if pr.RequiresVerification {
+		status = "pending"
+	}
+
+	form := UserForm{
+		Name:     name,
+		Email:    email,
+		RoleID:   pr.RoleID,
+		RoleName: pr.RoleName,
+		Status:   status,
+	}
+	if pr.RoleName == "mahasiswa" {
+		form.NIM = GetPtr(pr.ExtractNIM(email))
+	}
+	return form
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestProvisioningRuleMatches(t *testing.T) {
+	nim := sql.NullString{String: `^([0-9]{9})$`, Valid: true}
+
+	tests := []struct {
+		name  string
+		rule  ProvisioningRule
+		email string
+		want  bool
+	}{
+		{"mahasiswa cocok", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `[0-9]{9}@pnc\.ac\.id`, NIMPattern: nim}, "220101001@pnc.ac.id", true},
+		{"huruf besar dan spasi", ProvisioningRule{Domain: "PNC.ac.id", EmailPattern: `[0-9]{9}@pnc\.ac\.id`, NIMPattern: nim}, " 220101001@PNC.AC.ID ", true},
+		{"pola harus cocok seluruh alamat", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `[0-9]{9}@pnc\.ac\.id`}, "x220101001@pnc.ac.id", false},
+		{"pola sebagian tidak cukup", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `staf`}, "staf.keuangan@pnc.ac.id", false},
+		{"pola dengan jangkar tetap berlaku", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `^staf\..+@pnc\.ac\.id$`}, "staf.keuangan@pnc.ac.id", true},
+		{"domain lain", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `.+`}, "budi@gmail.com", false},
+		{"subdomain palsu", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `.+`}, "budi@evilpnc.ac.id", false},
+		{"NIM tidak terekstrak", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `.+@pnc\.ac\.id`, NIMPattern: nim}, "budi@pnc.ac.id", false},
+		{"regex rusak", ProvisioningRule{Domain: "pnc.ac.id", EmailPattern: `(`}, "budi@pnc.ac.id", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.email); got != tt.want {
+				t.Errorf("Matches(%q) = %v; want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvisioningRuleExtractNIM(t *testing.T) {
+	tests := []struct {
+		pattern string
+		email   string
+		want    string
+	}{
+		{`^([0-9]{9})$`, "220101001@pnc.ac.id", "220101001"},
+		{`^mhs\.([0-9]+)$`, "mhs.2201@pnc.ac.id", "2201"},
+		{`[0-9]+`, "budi2201@pnc.ac.id", "2201"},
+		{`^([0-9]{9})$`, "budi@pnc.ac.id", ""},
+		{"", "220101001@pnc.ac.id", ""},
+	}
+
+	for _, tt := range tests {
+		rule := ProvisioningRule{NIMPattern: sql.NullString{String: tt.pattern, Valid: tt.pattern != ""}}
+		if got := rule.ExtractNIM(tt.email); got != tt.want {
+			t.Errorf("ExtractNIM(%q, %q) = %q; want %q", tt.pattern, tt.email, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RegistrationRequest struct {
+	ID               int            `db:"id"`
+	Name             string         `db:"name"`
+	Email            string         `db:"email"`
+	RoleID           int            `db:"role_id"`
+	RoleName         string         `db:"role_name"`
+	NIM              sql.NullString `db:"nim"`
+	NIP              sql.NullString `db:"nip"`
+	NUPTK            sql.NullString `db:"nuptk"`
+	StudyProgramID   sql.NullInt64  `db:"study_program_id"`
+	StudyProgramName sql.NullString `db:"study_program_name"`
+	Status           string         `db:"status"`
+	ReviewNote       sql.NullString `db:"review_note"`
+	ReviewedBy       sql.NullInt64  `db:"reviewed_by"`
+	ReviewedAt       sql.NullTime   `db:"reviewed_at"`
+	CreatedAt        time.Time      `db:"created_at"`
+}
+
+type RegistrationForm struct {
+	Name           string
+	Email          string
+	RoleID         int
+	NIM            *string
+	NIP            *string
+	NUPTK          *string
+	StudyProgramID *int
+}
+
+const registrationSelect = `
+	SELECT rr.id, rr.name, rr.email, rr.role_id, r.role_name, rr.nim, rr.nip, rr.nuptk,
+		rr.study_program_id, sp.study_program_name, rr.status, rr.review_note,
+		rr.reviewed_by, rr.reviewed_at, rr.created_at
+	FROM registration_requests rr
+	JOIN roles r ON rr.role_id = r.id
+	LEFT JOIN study_programs sp ON rr.study_program_id = sp.id
+`
+
+// GetRegistrationRequests mengambil daftar pengajuan pendaftaran berdasarkan status.
+func GetRegistrationRequests(db *sqlx.DB, status string, page, pagesize int) ([]RegistrationRequest, error) {
+	offset := (page - 1) * pagesize
+
+	query := registrationSelect + ` WHERE 1=1 `
+	args := []interface{}{}
+
+	if status != "" {
+		query += ` AND rr.status = ? `
+		args = append(args, status)
+	}
+
+	query += ` ORDER BY rr.created_at ASC LIMIT ? OFFSET ? `
+	args = append(args, pagesize, offset)
+
+	var data []RegistrationRequest
+	err := db.Select(&data, query, args...)
+	return data, err
+}
+
+func FindRegistrationRequestByID(db *sqlx.DB, id int) (*RegistrationRequest, error) {
+	var rr RegistrationRequest
+	err := db.Get(&rr, registrationSelect+` WHERE rr.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &rr, nil
+}
+
+// FindPendingRegistrationByEmail mengembalikan pengajuan yang masih menunggu untuk email tersebut, nil jika tidak ada.
+func FindPendingRegistrationByEmail(db *sqlx.DB, email string) (*RegistrationRequest, error) {
+	var rr RegistrationRequest
+	err := db.Get(&rr, registrationSelect+` WHERE LOWER(rr.email) = LOWER(?) AND rr.status = 'pending' LIMIT 1`, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rr, nil
+}
+
+func CountPendingRegistrations(db *sqlx.DB) (int, error) {
+	var total int
+	err := db.Get(&total, `SELECT COUNT(*) FROM registration_requests WHERE status = 'pending'`)
+	return total, err
+}
+
+func CreateRegistrationRequest(db *sqlx.DB, form RegistrationForm) (int64, error) {
+	query := `INSERT INTO registration_requests (name, email, role_id, nim, nip, nuptk, study_program_id, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'pending', NOW())`
+	res, err := db.Exec(query, form.Name, form.Email, form.RoleID, form.NIM, form.NIP, form.NUPTK, form.StudyProgramID)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ReviewRegistrationRequest menandai pengajuan sebagai approved/rejected.
+// Hanya pengajuan berstatus pending yang dapat direview.
+func ReviewRegistrationRequest(db *sqlx.DB, id int, status string, reviewerID int, note string) error {
+	res, err := db.Exec(`UPDATE registration_requests
+		SET status = ?, review_note = ?, reviewed_by = ?, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND status = 'pending'`,
+		status, GetPtr(note), reviewerID, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ApproveRegistrationRequest membuat akun dari pengajuan dan menandainya disetujui dalam satu transaksi,
+// sehingga akun tidak pernah tercipta tanpa pengajuan ikut berubah status. Mengembalikan sql.ErrNoRows
+// jika pengajuan sudah tidak pending.
+func ApproveRegistrationRequest(db *sqlx.DB, rr *RegistrationRequest, reviewerID int) (userID int64, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var status string
+	if err = tx.Get(&status, `SELECT status FROM registration_requests WHERE id = ? FOR UPDATE`, rr.ID); err != nil {
+		return 0, err
+	}
+	if status != "pending" {
+		err = sql.ErrNoRows
+		return 0, err
+	}
+
+	userID, err = insertUser(tx, rr.ToUserForm())
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`UPDATE registration_requests
+		SET status = 'approved', review_note = NULL, reviewed_by = ?, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = ?`, reviewerID, rr.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// ToUserForm mengubah pengajuan menjadi UserForm yang siap diproses oleh CreateUser.
+func (rr *RegistrationRequest) ToUserForm() UserForm {
+	form := UserForm{
+		Name:     rr.Name,
+		Email:    rr.Email,
+		Status:   "aktif",
+		RoleID:   rr.RoleID,
+		RoleName: rr.RoleName,
+	}
+	if rr.NIM.Valid {
+		form.NIM = GetPtr(rr.NIM.String)
+	}
+	if rr.NIP.Valid {
+		form.NIP = GetPtr(rr.NIP.String)
+	}
+	if rr.NUPTK.Valid {
+		form.NUPTK = GetPtr(rr.NUPTK.String)
+	}
+	if rr.StudyProgramID.Valid {
+		prodiID := int(rr.StudyProgramID.Int64)
+		form.StudyProgramID = &prodiID
+	}
+	return form
+}
@@ -26,6 +26,17 @@ func GetAllRoles(db *sqlx.DB) ([]Role, error) {
 	return data, err
 }
 
+// GetRegistrableRoles mengambil role yang boleh dipilih pada pengajuan pendaftaran mandiri
+// (selain admin dan role yang memiliki izin panel admin).
+func GetRegistrableRoles(db *sqlx.DB) ([]Role, error) {
+	var data []Role
+	err := db.Select(&data, `SELECT id, role_name, description FROM roles
+		WHERE deleted_at IS NULL AND role_name <> 'admin'
+		AND id NOT IN (SELECT role_id FROM role_permissions)
+		ORDER BY id ASC`)
+	return data, err
+}
+
 func FindRoleByID(db *sqlx.DB, id int) (*Role, error) {
 	var r Role
 	// Ambil role yang belum dihapus (jika pakai soft delete)
@@ -35,10 +46,13 @@ func FindRoleByID(db *sqlx.DB, id int) (*Role, error) {
 	return &r, err
 }
 
-func CreateRole(db *sqlx.DB, name, description string) error {
+func CreateRole(db *sqlx.DB, name, description string) (int64, error) {
 	query := `INSERT INTO roles (role_name, description, created_at, updated_at) VALUES (?, ?, NOW(), NOW())`
-	_, err := db.Exec(query, name, description)
-	return err
+	res, err := db.Exec(query, name, description)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
 }
 
 func UpdateRole(db *sqlx.DB, id int, name, description string) error {
@@ -48,7 +62,14 @@ func UpdateRole(db *sqlx.DB, id int, name, description string) error {
 }
 
 func DeleteRole(db *sqlx.DB, id int) error {
-	query := `DELETE FROM roles WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
+	query := `UPDATE roles SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
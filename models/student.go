@@ -3,10 +3,64 @@ package models
 import (
 	"database/sql"
 
+	"github.com/jmoiron/sqlx"
 )
 
 type Student struct {
     ID       int            `db:"id"`
     UserID   int            `db:"user_id"`
     NIM      sql.NullString `db:"nim"`
-}
\ No newline at end of file
+    StudyProgramID sql.NullInt64 `db:"study_program_id"`
+    CohortYear     sql.NullInt64 `db:"cohort_year"`     // Tahun angkatan
+    AcademicStatus string        `db:"academic_status"` // aktif, cuti, lulus, do
+    MajorID        sql.NullInt64 `db:"major_id"`        // Join result dari prodi
+    AdvisorUserID  sql.NullInt64 `db:"advisor_user_id"` // Dosen wali (user id), join result
+}
+
+// AcademicStatus adalah pilihan status akademik mahasiswa.
+type AcademicStatus struct {
+	Key   string
+	Label string
+}
+
+var AcademicStatuses = []AcademicStatus{
+	{"aktif", "Aktif"},
+	{"cuti", "Cuti"},
+	{"lulus", "Lulus"},
+	{"do", "Drop Out (DO)"},
+}
+
+func IsValidAcademicStatus(key string) bool {
+	for _, s := range AcademicStatuses {
+		if s.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AcademicStatusLabel mengembalikan label status akademik, atau key-nya jika tidak dikenal.
+func AcademicStatusLabel(key string) string {
+	for _, s := range AcademicStatuses {
+		if s.Key == key {
+			return s.Label
+		}
+	}
+	return key
+}
+
+// findStudentByUserID mengambil data mahasiswa beserta jurusan dari prodinya dan dosen walinya.
+func findStudentByUserID(db *sqlx.DB, userID int) (*Student, error) {
+	var s Student
+	err := db.Get(&s, `
+		SELECT s.id, s.user_id, s.nim, s.study_program_id, s.cohort_year, s.academic_status, sp.major_id,
+			sa.advisor_user_id
+		FROM students s
+		LEFT JOIN study_programs sp ON s.study_program_id = sp.id
+		LEFT JOIN student_advisors sa ON sa.student_user_id = s.user_id
+		WHERE s.user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
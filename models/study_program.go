@@ -1,6 +1,10 @@
 package models
 
-import "github.com/jmoiron/sqlx"
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
 
 type StudyProgram struct {
 	ID        int    `db:"id"`
@@ -47,7 +51,14 @@ func UpdateStudyProgram(db *sqlx.DB, id int, name string, majorID int) error {
 }
 
 func DeleteStudyProgram(db *sqlx.DB, id int) error {
-	query := `DELETE FROM study_programs WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
+	query := `UPDATE study_programs SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrTrashInUse dikembalikan saat data di Trash masih dipakai data aktif sehingga tidak boleh dihapus permanen.
+var ErrTrashInUse = errors.New("data masih digunakan oleh data aktif")
+
+type TrashItem struct {
+	ID        int            `db:"id"`
+	Name      string         `db:"name"`
+	Detail    sql.NullString `db:"detail"`
+	DeletedAt time.Time      `db:"deleted_at"`
+}
+
+// TrashEntity mendefinisikan tabel yang mendukung soft delete beserta query untuk menampilkan dan memeriksanya.
+type TrashEntity struct {
+	Key    string
+	Label  string
+	table  string
+	name   string
+	detail string
+	join   string
+	// inUse menghitung data aktif yang akan ikut terhapus oleh cascade saat purge.
+	inUse string
+}
+
+var TrashEntities = []TrashEntity{
+	{
+		Key:    "users",
+		Label:  "Pengguna",
+		table:  "users",
+		name:   "t.name",
+		detail: "t.email",
+	},
+	{
+		Key:    "majors",
+		Label:  "Jurusan",
+		table:  "majors",
+		name:   "t.major_name",
+		detail: "NULL",
+		inUse: `SELECT (SELECT COUNT(*) FROM study_programs WHERE major_id = ? AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM lecturer_positions WHERE major_id = ?)
+			+ (SELECT COUNT(*) FROM user_groups WHERE rule_major_id = ?)
+			+ (SELECT COUNT(*) FROM application_policies WHERE major_id = ?)
+			+ (SELECT COUNT(*) FROM application_position_access WHERE major_id = ?)
+			+ (SELECT COUNT(*) FROM application_student_access WHERE major_id = ?)
+			+ (SELECT COUNT(*) FROM admin_scopes WHERE major_id = ?)`,
+	},
+	{
+		Key:    "study_programs",
+		Label:  "Program Studi",
+		table:  "study_programs",
+		name:   "t.study_program_name",
+		detail: "m.major_name",
+		join:   "LEFT JOIN majors m ON t.major_id = m.id",
+		inUse: `SELECT (SELECT COUNT(*) FROM students WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM lecturers WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM lecturer_positions WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM user_groups WHERE rule_study_program_id = ?)
+			+ (SELECT COUNT(*) FROM application_policies WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM application_position_access WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM application_student_access WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM admin_scopes WHERE study_program_id = ?)
+			+ (SELECT COUNT(*) FROM registration_requests WHERE study_program_id = ? AND status = 'pending')`,
+	},
+	{
+		Key:    "positions",
+		Label:  "Jabatan",
+		table:  "positions",
+		name:   "t.position_name",
+		detail: "NULL",
+		inUse: `SELECT (SELECT COUNT(*) FROM lecturer_positions WHERE position_id = ?)
+			+ (SELECT COUNT(*) FROM user_groups WHERE rule_position_id = ?)
+			+ (SELECT COUNT(*) FROM application_policies WHERE position_id = ?)
+			+ (SELECT COUNT(*) FROM application_position_access WHERE position_id = ?)`,
+	},
+	{
+		Key:    "roles",
+		Label:  "Role",
+		table:  "roles",
+		name:   "t.role_name",
+		detail: "t.description",
+		// user_roles milik user di Trash ikut dihitung: tanpa role, user tersebut tidak dapat dimuat lagi setelah dipulihkan
+		inUse: `SELECT (SELECT COUNT(*) FROM user_roles WHERE role_id = ?)
+			+ (SELECT COUNT(*) FROM role_permissions WHERE role_id = ?)
+			+ (SELECT COUNT(*) FROM application_policies WHERE role_id = ?)
+			+ (SELECT COUNT(*) FROM application_role_access WHERE role_id = ?)
+			+ (SELECT COUNT(*) FROM application_availability_windows WHERE role_id = ?)
+			+ (SELECT COUNT(*) FROM provisioning_rules WHERE role_id = ?)
+			+ (SELECT COUNT(*) FROM registration_requests WHERE role_id = ?)`,
+	},
+}
+
+func FindTrashEntity(key string) (*TrashEntity, bool) {
+	for i := range TrashEntities {
+		if TrashEntities[i].Key == key {
+			return &TrashEntities[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetTrashItems mengambil data yang sudah dihapus (soft delete) untuk satu entitas.
+func GetTrashItems(db *sqlx.DB, e *TrashEntity) ([]TrashItem, error) {
+	query := fmt.Sprintf(`SELECT t.id, %s AS name, %s AS detail, t.deleted_at
+		FROM %s t %s
+		WHERE t.deleted_at IS NOT NULL
+		ORDER BY t.deleted_at DESC`, e.name, e.detail, e.table, e.join)
+
+	var data []TrashItem
+	err := db.Select(&data, query)
+	return data, err
+}
+
+// RestoreTrashItem memulihkan data dari Trash.
+func RestoreTrashItem(db *sqlx.DB, e *TrashEntity, id int) error {
+	query := fmt.Sprintf(`UPDATE %s SET deleted_at = NULL, updated_at = NOW() WHERE id = ? AND deleted_at IS NOT NULL`, e.table)
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PurgeTrashItem menghapus permanen data yang sudah ada di Trash.
+// Ditolak dengan ErrTrashInUse jika cascade akan ikut menghapus data yang masih aktif.
+func PurgeTrashItem(db *sqlx.DB, e *TrashEntity, id int) error {
+	if e.inUse != "" {
+		args := make([]interface{}, strings.Count(e.inUse, "?"))
+		for i := range args {
+			args[i] = id
+		}
+
+		var count int
+		if err := db.Get(&count, e.inUse, args...); err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrTrashInUse
+		}
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND deleted_at IS NOT NULL`, e.table)
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// trashHistoryTables adalah tabel riwayat yang boleh kehilangan referensinya (ON DELETE SET NULL) saat purge.
+var trashHistoryTables = map[string]bool{
+	"application_launches": true,
+	"user_logins":          true,
+}
+
+// TestTrashInUseCoversCascades memastikan setiap foreign key yang ikut menghapus atau mengosongkan data saat
+// purge (ON DELETE CASCADE / SET NULL) diperiksa oleh inUse, sehingga purge tidak diam-diam menghapus data aktif.
+func TestTrashInUseCoversCascades(t *testing.T) {
+	schema, err := os.ReadFile("../database/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alterRe := regexp.MustCompile("(?s)ALTER TABLE `(\\w+)`(.*?);")
+	fkRe := regexp.MustCompile("FOREIGN KEY \\(`(\\w+)`\\) REFERENCES `(\\w+)` \\(`id`\\) ON DELETE (CASCADE|SET NULL)")
+	spaceRe := regexp.MustCompile(`\s+`)
+
+	for _, e := range TrashEntities {
+		// Purge user memang menghapus seluruh data milik user tersebut
+		if e.Key == "users" {
+			continue
+		}
+		inUse := spaceRe.ReplaceAllString(e.inUse, " ")
+
+		for _, alter := range alterRe.FindAllStringSubmatch(string(schema), -1) {
+			child := alter[1]
+			for _, fk := range fkRe.FindAllStringSubmatch(alter[2], -1) {
+				column, parent := fk[1], fk[2]
+				if parent != e.table || trashHistoryTables[child] {
+					continue
+				}
+				if !strings.Contains(inUse, "FROM "+child+" WHERE "+column+" = ?") {
+					t.Errorf("purge %s: %s.%s (ON DELETE %s) tidak diperiksa inUse", e.Key, child, column, fk[3])
+				}
+			}
+		}
+	}
+}
+
+func TestTrashEntityLookup(t *testing.T) {
+	for _, e := range TrashEntities {
+		got, ok := FindTrashEntity(e.Key)
+		if !ok || got.table != e.table {
+			t.Errorf("FindTrashEntity(%q) = %+v, %v", e.Key, got, ok)
+		}
+	}
+	if _, ok := FindTrashEntity("applications"); ok {
+		t.Error("FindTrashEntity menerima entitas yang tidak terdaftar")
+	}
+}
@@ -14,9 +14,9 @@ type User struct {
 	Email        string         `db:"email"`
 	Status       string         `db:"status"`
 	Avatar       sql.NullString `db:"avatar"`
-	GoogleAvatar sql.NullString `db: "googleavatar"`
+	GoogleAvatar sql.NullString `db:"google_avatar"`
 	Address      sql.NullString `db:"address"`
-	Phone        sql.NullString `db:"address"`
+	Phone        sql.NullString `db:"phone_number"`
 }
 type UserRole struct {
 	RoleID int    `db:"role_id"`
@@ -27,8 +27,20 @@ type FullUser struct {
 	Roles     []UserRole
 	Student   *Student
 	Lecturer  *Lecturer
-	Positions []LecturerPosition
+	Positions []LecturerPosition // Hanya jabatan yang sedang berlaku
 }
+
+// HomebaseStudyProgramID mengembalikan id prodi homebase mahasiswa/dosen, 0 jika belum diisi.
+func (fu *FullUser) HomebaseStudyProgramID() int {
+	if fu.Student != nil && fu.Student.StudyProgramID.Valid {
+		return int(fu.Student.StudyProgramID.Int64)
+	}
+	if fu.Lecturer != nil && fu.Lecturer.StudyProgramID.Valid {
+		return int(fu.Lecturer.StudyProgramID.Int64)
+	}
+	return 0
+}
+
 type UserListItem struct {
 	ID     int            `db:"id"`
 	Name   string         `db:"name"`
@@ -57,9 +69,24 @@ type UserForm struct {
 	NIP   *string
 	NUPTK *string
 
+	// Prodi homebase mahasiswa/dosen, dipakai untuk lingkup admin terdelegasi
+	StudyProgramID *int
+
+	// Khusus mahasiswa
+	CohortYear     *int
+	AcademicStatus string
+
 	Positions []LecturerPosition
 }
 
+// studentAcademicStatus mengembalikan status akademik mahasiswa, default 'aktif' (mis. dari import atau pendaftaran).
+func (f UserForm) studentAcademicStatus() string {
+	if f.AcademicStatus == "" {
+		return "aktif"
+	}
+	return f.AcademicStatus
+}
+
 // =================
 // READ & FIND FUNCTIONS
 // =================
@@ -101,23 +128,23 @@ func FindUserByEmail(db *sqlx.DB, email string) (*FullUser, error) {
 
 	// Ambil data tambahan berdasarkan peran
 	if role.Name == "mahasiswa" {
-		var s Student
-		err := db.Get(&s, "SELECT id, user_id, nim FROM students WHERE user_id = ?", fu.ID)
+		s, err := findStudentByUserID(db, fu.ID)
 		if err == nil {
-			fu.Student = &s
+			fu.Student = s
 		}
 	} else if role.Name == "dosen" {
 		var l Lecturer
-		err := db.Get(&l, "SELECT id, user_id, nip, nuptk FROM lecturers WHERE user_id = ?", fu.ID)
+		err := db.Get(&l, "SELECT id, user_id, nip, nuptk, study_program_id FROM lecturers WHERE user_id = ?", fu.ID)
 		if err == nil {
 			fu.Lecturer = &l
 		}
 
-		// Ambil posisi dosen jika ada
-		var positions []LecturerPosition
-		err = db.Select(&positions, "SELECT id, lecturer_id, position_id, major_id, study_program_id FROM lecturer_positions WHERE lecturer_id = ?", fu.Lecturer.ID)
-		if err == nil {
-			fu.Positions = positions
+		// Hanya jabatan yang sedang berlaku yang dihitung untuk akses aplikasi dan klaim token
+		if fu.Lecturer != nil {
+			positions, err := GetActiveLecturerPositions(db, fu.Lecturer.ID)
+			if err == nil {
+				fu.Positions = positions
+			}
 		}
 	}
 
@@ -161,29 +188,30 @@ func FindUserByID(db *sqlx.DB, id int) (*FullUser, error) {
 	fu.Roles = append(fu.Roles, role)
 
 	if role.Name == "mahasiswa" {
-		var s Student
-		err := db.Get(&s, "SELECT id, user_id, nim FROM students WHERE user_id = ?", fu.ID)
+		s, err := findStudentByUserID(db, fu.ID)
 		if err == nil {
-			fu.Student = &s
+			fu.Student = s
 		}
 	} else if role.Name == "dosen" {
 		var l Lecturer
-		err := db.Get(&l, "SELECT id, user_id, nip, nuptk FROM lecturers WHERE user_id = ?", fu.ID)
+		err := db.Get(&l, "SELECT id, user_id, nip, nuptk, study_program_id FROM lecturers WHERE user_id = ?", fu.ID)
 		if err == nil {
 			fu.Lecturer = &l
 		}
 
-		var positions []LecturerPosition
-		err = db.Select(&positions, "SELECT id, lecturer_id, position_id, major_id, study_program_id FROM lecturer_positions WHERE lecturer_id = ?", fu.Lecturer.ID)
-		if err == nil {
-			fu.Positions = positions
+		// Hanya jabatan yang sedang berlaku yang dihitung untuk akses aplikasi dan klaim token
+		if fu.Lecturer != nil {
+			positions, err := GetActiveLecturerPositions(db, fu.Lecturer.ID)
+			if err == nil {
+				fu.Positions = positions
+			}
 		}
 	}
 
 	return &fu, nil
 }
 
-func GetAllUsers(db *sqlx.DB, page, pagesize int, search, role string) ([]UserListItem, error) {
+func GetAllUsers(db *sqlx.DB, page, pagesize int, search, role string, scope *AdminScope) ([]UserListItem, error) {
 
 	offset := (page - 1) * pagesize
 
@@ -200,17 +228,11 @@ func GetAllUsers(db *sqlx.DB, page, pagesize int, search, role string) ([]UserLi
         WHERE u.deleted_at IS NULL
     `
 
-	args := []interface{}{}
-
-	if search != "" {
-		query += " AND (u.name LIKE ? OR u.email LIKE ?) "
-		args = append(args, "%"+search+"%", "%"+search+"%")
-	}
-
-	if role != "" {
-		query += " AND r.role_name = ? "
-		args = append(args, role)
+	filter, args, err := userListFilter(search, role, scope)
+	if err != nil {
+		return nil, err
 	}
+	query += filter
 
 	query += `
         ORDER BY u.id ASC
@@ -219,7 +241,7 @@ func GetAllUsers(db *sqlx.DB, page, pagesize int, search, role string) ([]UserLi
 	args = append(args, pagesize, offset)
 
 	var users []UserListItem
-	err := db.Select(&users, query, args...)
+	err = db.Select(&users, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +249,33 @@ func GetAllUsers(db *sqlx.DB, page, pagesize int, search, role string) ([]UserLi
 	return users, nil
 }
 
+// userListFilter menyusun kondisi pencarian, filter role dan lingkup admin yang dipakai bersama oleh list dan export user.
+func userListFilter(search, role string, scope *AdminScope) (string, []interface{}, error) {
+	query := ""
+	args := []interface{}{}
+
+	if search != "" {
+		query += " AND (u.name LIKE ? OR u.email LIKE ?) "
+		args = append(args, "%"+search+"%", "%"+search+"%")
+	}
+
+	if role != "" {
+		query += " AND r.role_name = ? "
+		args = append(args, role)
+	}
+
+	if scope != nil {
+		cond, scopeArgs, err := scope.userCondition()
+		if err != nil {
+			return "", nil, err
+		}
+		query += cond
+		args = append(args, scopeArgs...)
+	}
+
+	return query, args, nil
+}
+
 func GetContact(db *sqlx.DB, email string) (*AdminContact, error) {
 
 	var contact AdminContact
@@ -261,6 +310,20 @@ func CreateUser(db *sqlx.DB, form UserForm) (int64, error) {
 		}
 	}()
 
+	userID, err := insertUser(tx, form)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// insertUser menyimpan user beserta role, data mahasiswa/dosen, dan jabatan di dalam transaksi yang sudah dibuka.
+func insertUser(tx *sqlx.Tx, form UserForm) (int64, error) {
 	query := `INSERT INTO users (name, email, status, address, phone_number) VALUES (?, ?, ?, ?, ?)`
 	res, err := tx.Exec(query, form.Name, form.Email, form.Status, form.Address, form.Phone)
 	if err != nil {
@@ -280,14 +343,14 @@ func CreateUser(db *sqlx.DB, form UserForm) (int64, error) {
 	}
 
 	if form.RoleName == "mahasiswa" {
-		query = `INSERT INTO students (user_id, nim) VALUES (?, ?)`
-		_, err := tx.Exec(query, userID, form.NIM)
+		query = `INSERT INTO students (user_id, nim, study_program_id, cohort_year, academic_status) VALUES (?, ?, ?, ?, ?)`
+		_, err := tx.Exec(query, userID, form.NIM, form.StudyProgramID, form.CohortYear, form.studentAcademicStatus())
 		if err != nil {
 			return 0, err
 		}
 	} else if form.RoleName == "dosen" {
-		query = `INSERT INTO lecturers (user_id, nip, nuptk) VALUES (?, ?, ?)`
-		res2, err := tx.Exec(query, userID, form.NIP, form.NUPTK)
+		query = `INSERT INTO lecturers (user_id, nip, nuptk, study_program_id) VALUES (?, ?, ?, ?)`
+		res2, err := tx.Exec(query, userID, form.NIP, form.NUPTK, form.StudyProgramID)
 		if err != nil {
 			return 0, err
 		}
@@ -304,10 +367,6 @@ func CreateUser(db *sqlx.DB, form UserForm) (int64, error) {
 			}
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		return 0, err
-	}
 	return userID, nil
 }
 
@@ -367,6 +426,21 @@ func UpdateUser(db *sqlx.DB, form UserForm) error {
 		return err
 	}
 
+	// Status pengingat & berakhirnya jabatan dibawa ke baris baru agar edit profil tidak memicu ulang job masa jabatan
+	var previous []positionState
+	err = tx.Select(&previous, `SELECT lp.position_id, lp.major_id, lp.study_program_id,
+			DATE_FORMAT(lp.start_date, '%Y-%m-%d') AS start_date, DATE_FORMAT(lp.end_date, '%Y-%m-%d') AS end_date,
+			lp.expiry_notified_at, lp.ended_at
+		FROM lecturer_positions lp JOIN lecturers l ON lp.lecturer_id = l.id WHERE l.user_id = ?`, form.ID)
+	if err != nil {
+		return err
+	}
+	carried := map[string][]positionState{}
+	for _, p := range previous {
+		key := positionKey(p.PositionID, p.MajorID, p.StudyProgramID, p.StartDate.String, p.EndDate.String)
+		carried[key] = append(carried[key], p)
+	}
+
 	_, err = tx.Exec(`DELETE FROM user_roles WHERE user_id=?`, form.ID)
 	_, err = tx.Exec(`DELETE FROM students WHERE user_id=?`, form.ID)
 	_, err = tx.Exec(`DELETE FROM lecturer_positions WHERE lecturer_id IN (SELECT id FROM lecturers WHERE user_id=?)`, form.ID)
@@ -380,18 +454,26 @@ func UpdateUser(db *sqlx.DB, form UserForm) error {
 	}
 
 	if form.RoleName == "mahasiswa" {
-		_, err = tx.Exec(`INSERT INTO students (user_id, nim) VALUES (?, ?)`, form.ID, form.NIM)
+		_, err = tx.Exec(`INSERT INTO students (user_id, nim, study_program_id, cohort_year, academic_status) VALUES (?, ?, ?, ?, ?)`,
+			form.ID, form.NIM, form.StudyProgramID, form.CohortYear, form.studentAcademicStatus())
 		if err != nil { return err }
 	} else if form.RoleName == "dosen" {
-		res, err := tx.Exec(`INSERT INTO lecturers (user_id, nip, nuptk) VALUES (?, ?, ?)`, form.ID, form.NIP, form.NUPTK)
+		res, err := tx.Exec(`INSERT INTO lecturers (user_id, nip, nuptk, study_program_id) VALUES (?, ?, ?, ?)`, form.ID, form.NIP, form.NUPTK, form.StudyProgramID)
 		if err != nil { return err }
 		
 		lecturerID, _ := res.LastInsertId()
 		
 		for _, pos := range form.Positions {
-			_, err = tx.Exec(`INSERT INTO lecturer_positions (lecturer_id, position_id, major_id, study_program_id, start_date, end_date) 
-			VALUES (?, ?, ?, ?, ?, ?)`,
-				lecturerID, pos.PositionID, pos.MajorID, pos.StudyProgramID, pos.StartDate, pos.EndDate)
+			// Jabatan yang tanggalnya diubah (mis. diperpanjang) dianggap baru sehingga pengingat dikirim lagi
+			var state positionState
+			key := positionKey(pos.PositionID, pos.MajorID, pos.StudyProgramID, derefString(pos.StartDate), derefString(pos.EndDate))
+			if matches := carried[key]; len(matches) > 0 {
+				state, carried[key] = matches[0], matches[1:]
+			}
+
+			_, err = tx.Exec(`INSERT INTO lecturer_positions (lecturer_id, position_id, major_id, study_program_id, start_date, end_date, expiry_notified_at, ended_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				lecturerID, pos.PositionID, pos.MajorID, pos.StudyProgramID, pos.StartDate, pos.EndDate, state.ExpiryNotifiedAt, state.EndedAt)
 			if err != nil { return err }
 		}
 	}
@@ -399,35 +481,63 @@ func UpdateUser(db *sqlx.DB, form UserForm) error {
 	return tx.Commit()
 }
 
+// positionState adalah status job masa jabatan dari baris lecturer_positions sebelum diedit.
+type positionState struct {
+	PositionID       int            `db:"position_id"`
+	MajorID          sql.NullInt64  `db:"major_id"`
+	StudyProgramID   sql.NullInt64  `db:"study_program_id"`
+	StartDate        sql.NullString `db:"start_date"`
+	EndDate          sql.NullString `db:"end_date"`
+	ExpiryNotifiedAt sql.NullTime   `db:"expiry_notified_at"`
+	EndedAt          sql.NullTime   `db:"ended_at"`
+}
+
+func positionKey(positionID int, majorID, studyProgramID sql.NullInt64, startDate, endDate string) string {
+	return fmt.Sprintf("%d|%d|%d|%s|%s", positionID, majorID.Int64, studyProgramID.Int64, startDate, endDate)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // =================
 // DELETE FUNCTIONS
 // =================
+// DeleteUser memindahkan user ke Trash (soft delete). Data mahasiswa/dosen tetap disimpan agar bisa dipulihkan.
 func DeleteUser(db *sqlx.DB, userID int) error {
-	tx, err := db.Beginx()
+	res, err := db.Exec(`UPDATE users SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`, userID)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-	_, err = tx.Exec(`DELETE FROM users WHERE id = ?`, userID)
-	if err != nil {
-		return err
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
 	}
+	return nil
+}
 
-	_, err = tx.Exec(`DELETE FROM lecturers WHERE user_id = ?`, userID)
+// SetUserStatus mengubah status akun (aktif/nonaktif) pengguna.
+func SetUserStatus(db *sqlx.DB, userID int, status string) error {
+	res, err := db.Exec(`UPDATE users SET status = ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`, status, userID)
 	if err != nil {
 		return err
 	}
-
-	_, err = tx.Exec(`DELETE FROM students WHERE user_id = ?`, userID)
-	if err != nil {
-		return err
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
 	}
-	err = tx.Commit()
-	return err
+	return nil
+}
+
+// IsEmailInTrash mengecek apakah email dimiliki user yang sudah dihapus (soft delete).
+// Email tetap unik di tabel users sehingga email tersebut belum bisa dipakai ulang.
+func IsEmailInTrash(db *sqlx.DB, email string) (bool, error) {
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM users WHERE LOWER(TRIM(email)) = LOWER(?) AND deleted_at IS NOT NULL`, email)
+	return count > 0, err
 }
 
 // ==========================================
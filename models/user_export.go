@@ -0,0 +1,168 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// UserExportRow adalah satu baris data export user beserta kolom khusus mahasiswa/dosen.
+type UserExportRow struct {
+	ID         int            `db:"id"`
+	Name       string         `db:"name"`
+	Email      string         `db:"email"`
+	Status     string         `db:"status"`
+	Role       string         `db:"role"`
+	Address    sql.NullString `db:"address"`
+	Phone      sql.NullString `db:"phone_number"`
+	NIM        sql.NullString `db:"nim"`
+	LecturerID sql.NullInt64  `db:"lecturer_id"`
+	NIP        sql.NullString `db:"nip"`
+	NUPTK      sql.NullString `db:"nuptk"`
+	Positions  string         `db:"-"`
+}
+
+// Header kolom file export, urutannya sama dengan UserExportRow.Record.
+var UserExportColumns = []string{"ID", "Nama", "Email", "Status", "Role", "NIM", "NIP", "NUPTK", "Jabatan", "Alamat", "Telepon"}
+
+func (u UserExportRow) Record() []string {
+	return []string{
+		fmt.Sprint(u.ID),
+		u.Name,
+		u.Email,
+		u.Status,
+		u.Role,
+		u.NIM.String,
+		u.NIP.String,
+		u.NUPTK.String,
+		u.Positions,
+		u.Address.String,
+		u.Phone.String,
+	}
+}
+
+// CSVRecord sama dengan Record, dengan nilai yang aman dibuka di spreadsheet (lihat csvCell).
+// XLSX tidak memerlukannya karena sel ditulis bertipe teks dan tidak pernah dievaluasi sebagai formula.
+func (u UserExportRow) CSVRecord() []string {
+	record := u.Record()
+	for i, v := range record {
+		record[i] = csvCell(v)
+	}
+	return record
+}
+
+// csvCell mencegah formula injection: nilai yang diawali karakter formula spreadsheet
+// diberi awalan petik agar dibaca sebagai teks saat file CSV dibuka di Excel/Sheets.
+func csvCell(v string) string {
+	if v != "" && strings.ContainsAny(v[:1], "=+-@\t\r") {
+		return "'" + v
+	}
+	return v
+}
+
+// userExportFrom adalah sumber data export; dipakai juga untuk membatasi jabatan yang dimuat.
+const userExportFrom = `
+        FROM users u
+        JOIN user_roles ur ON ur.user_id = u.id
+        JOIN roles r ON r.id = ur.role_id
+        LEFT JOIN students s ON s.user_id = u.id
+        LEFT JOIN lecturers l ON l.user_id = u.id
+        WHERE u.deleted_at IS NULL
+    `
+
+// StreamUsersForExport membaca user sesuai filter list secara bertahap dan memanggil fn untuk setiap baris,
+// sehingga data tidak perlu dimuat seluruhnya ke memori.
+func StreamUsersForExport(db *sqlx.DB, search, role string, scope *AdminScope, fn func(UserExportRow) error) error {
+	query := `
+        SELECT
+            u.id,
+            u.name,
+            u.email,
+            u.status,
+            r.role_name AS role,
+            u.address,
+            u.phone_number,
+            s.nim,
+            l.id AS lecturer_id,
+            l.nip,
+            l.nuptk
+    ` + userExportFrom
+
+	filter, args, err := userListFilter(search, role, scope)
+	if err != nil {
+		return err
+	}
+	query += filter + ` ORDER BY u.id ASC`
+
+	// Jabatan dimuat sekali sebelum cursor dibuka agar tidak ada query per baris
+	positions, err := lecturerPositionsForExport(db, filter, args)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u UserExportRow
+		if err := rows.StructScan(&u); err != nil {
+			return err
+		}
+
+		if u.LecturerID.Valid {
+			u.Positions = formatExportPositions(positions[int(u.LecturerID.Int64)])
+		}
+
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// lecturerPositionsForExport mengambil jabatan yang sedang berlaku milik dosen yang ikut diexport
+// (filter dan args sama dengan query export), dikelompokkan per lecturer_id.
+func lecturerPositionsForExport(db *sqlx.DB, filter string, args []interface{}) (map[int][]LecturerPositionDetail, error) {
+	var rows []struct {
+		LecturerID int `db:"lecturer_id"`
+		LecturerPositionDetail
+	}
+	err := db.Select(&rows, `SELECT
+		lp.lecturer_id,
+		p.position_name AS positionname,
+		COALESCE(m.major_name, sp.study_program_name) AS scopename
+	FROM lecturer_positions lp
+	JOIN positions p ON lp.position_id = p.id
+	LEFT JOIN majors m ON lp.major_id = m.id
+	LEFT JOIN study_programs sp ON lp.study_program_id = sp.id
+	WHERE `+activePositionCondition+`
+	AND lp.lecturer_id IN (SELECT l.id `+userExportFrom+filter+`)
+	ORDER BY lp.lecturer_id, lp.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := map[int][]LecturerPositionDetail{}
+	for _, row := range rows {
+		positions[row.LecturerID] = append(positions[row.LecturerID], row.LecturerPositionDetail)
+	}
+	return positions, nil
+}
+
+func formatExportPositions(positions []LecturerPositionDetail) string {
+	var parts []string
+	for _, p := range positions {
+		if p.ScopeName.Valid {
+			parts = append(parts, fmt.Sprintf("%s (%s)", p.PositionName, p.ScopeName.String))
+		} else {
+			parts = append(parts, p.PositionName)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Budi", "Budi"},
+		{"=HYPERLINK(\"http://evil.test\")", "'=HYPERLINK(\"http://evil.test\")"},
+		{"+62812345678", "'+62812345678"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+		{"Jl. Merdeka -5", "Jl. Merdeka -5"},
+	}
+
+	for _, tt := range tests {
+		if got := csvCell(tt.value); got != tt.want {
+			t.Errorf("csvCell(%q) = %q; want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUserExportRowRecords(t *testing.T) {
+	u := UserExportRow{
+		ID:    7,
+		Name:  "=cmd",
+		Email: "budi@pnc.ac.id",
+		Phone: sql.NullString{String: "+62812345678", Valid: true},
+	}
+
+	// XLSX memakai Record apa adanya; hanya CSV yang diberi awalan petik
+	record := u.Record()
+	csv := u.CSVRecord()
+	if len(record) != len(UserExportColumns) || len(csv) != len(UserExportColumns) {
+		t.Fatalf("jumlah kolom = %d/%d; want %d", len(record), len(csv), len(UserExportColumns))
+	}
+
+	phone := len(UserExportColumns) - 1
+	if record[1] != "=cmd" || record[phone] != "+62812345678" {
+		t.Errorf("Record() mengubah nilai: %q", record)
+	}
+	if csv[1] != "'=cmd" || csv[phone] != "'+62812345678" || csv[2] != "budi@pnc.ac.id" {
+		t.Errorf("CSVRecord() = %q", csv)
+	}
+}
@@ -0,0 +1,371 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportRow adalah satu baris hasil pembacaan file import beserta error validasinya.
+type ImportRow struct {
+	Line      int
+	Form      UserForm
+	Positions string
+	Errors    []string
+}
+
+type ImportResult struct {
+	Imported  int
+	FailedRow int
+}
+
+// Kolom yang dikenali pada header file import (tidak case-sensitive).
+var ImportColumns = []string{"name", "email", "role", "status", "nim", "nip", "nuptk", "address", "phone", "positions", "study_program"}
+
+// BuildImportRows memetakan record CSV/XLSX (baris pertama = header) menjadi UserForm,
+// lalu memvalidasi field wajib, duplikasi di dalam file, dan bentrok dengan data yang sudah ada.
+func BuildImportRows(db *sqlx.DB, records [][]string) ([]ImportRow, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file kosong")
+	}
+
+	header := map[string]int{}
+	for i, h := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, col := range []string{"name", "email", "role"} {
+		if _, ok := header[col]; !ok {
+			return nil, fmt.Errorf("kolom wajib '%s' tidak ditemukan pada header", col)
+		}
+	}
+
+	lookup, err := newImportLookup(db)
+	if err != nil {
+		return nil, err
+	}
+
+	get := func(rec []string, col string) string {
+		i, ok := header[col]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var rows []ImportRow
+	for idx, rec := range records[1:] {
+		if isBlankRecord(rec) {
+			continue
+		}
+
+		row := ImportRow{Line: idx + 2, Positions: get(rec, "positions")}
+		roleName := strings.ToLower(get(rec, "role"))
+
+		row.Form = UserForm{
+			Name:     get(rec, "name"),
+			Email:    strings.ToLower(get(rec, "email")),
+			RoleName: roleName,
+			Status:   strings.ToLower(get(rec, "status")),
+			Address:  GetPtr(get(rec, "address")),
+			Phone:    GetPtr(get(rec, "phone")),
+		}
+		if row.Form.Status == "" {
+			row.Form.Status = "aktif"
+		}
+
+		if row.Form.Name == "" {
+			row.Errors = append(row.Errors, "Nama wajib diisi")
+		}
+		if row.Form.Email == "" || !strings.Contains(row.Form.Email, "@") {
+			row.Errors = append(row.Errors, "Email tidak valid")
+		}
+		if row.Form.Status != "aktif" && row.Form.Status != "nonaktif" && row.Form.Status != "pending" {
+			row.Errors = append(row.Errors, "Status harus aktif, nonaktif, atau pending")
+		}
+
+		// Prodi homebase wajib untuk import oleh admin terdelegasi (lihat validasi lingkup di controller)
+		if prodi := get(rec, "study_program"); prodi != "" {
+			if id, ok := lookup.prodis[strings.ToLower(prodi)]; ok {
+				row.Form.StudyProgramID = &id
+			} else {
+				row.Errors = append(row.Errors, fmt.Sprintf("Prodi '%s' tidak dikenal", prodi))
+			}
+		}
+
+		roleID, ok := lookup.roles[roleName]
+		if !ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("Role '%s' tidak dikenal", get(rec, "role")))
+		}
+		row.Form.RoleID = roleID
+
+		if roleName == "mahasiswa" {
+			row.Form.NIM = GetPtr(get(rec, "nim"))
+			if row.Form.NIM == nil {
+				row.Errors = append(row.Errors, "NIM wajib diisi untuk Mahasiswa")
+			}
+		} else if roleName == "dosen" {
+			row.Form.NIP = GetPtr(get(rec, "nip"))
+			row.Form.NUPTK = GetPtr(get(rec, "nuptk"))
+			if row.Form.NIP == nil || row.Form.NUPTK == nil {
+				row.Errors = append(row.Errors, "Dosen wajib memiliki NIP dan NUPTK")
+			}
+
+			positions, errs := lookup.parsePositions(row.Positions)
+			row.Form.Positions = positions
+			row.Errors = append(row.Errors, errs...)
+		} else if row.Positions != "" {
+			row.Errors = append(row.Errors, "Jabatan hanya dapat diisi untuk Dosen")
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := validateImportUniqueness(db, rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// ImportUsers menyimpan baris import per chunk, masing-masing dalam satu transaksi,
+// memakai logika yang sama dengan CreateUser. Proses berhenti pada chunk pertama yang gagal.
+func ImportUsers(db *sqlx.DB, rows []ImportRow, chunkSize int) (ImportResult, error) {
+	var result ImportResult
+
+	for start := 0; start < len(rows); start += chunkSize {
+		end := start + chunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		n, failed, err := importChunk(db, rows[start:end])
+		if err != nil {
+			result.FailedRow = failed
+			return result, err
+		}
+		result.Imported += n
+	}
+
+	return result, nil
+}
+
+func importChunk(db *sqlx.DB, rows []ImportRow) (n int, failedLine int, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, row := range rows {
+		if _, err = insertUser(tx, row.Form); err != nil {
+			return 0, row.Line, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return len(rows), 0, nil
+}
+
+// HasImportErrors mengecek apakah ada baris yang gagal validasi.
+func HasImportErrors(rows []ImportRow) bool {
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// =================
+// HELPER IMPORT
+// =================
+
+type importLookup struct {
+	roles     map[string]int
+	positions map[string]int
+	majors    map[string]int
+	prodis    map[string]int
+}
+
+func newImportLookup(db *sqlx.DB) (*importLookup, error) {
+	l := &importLookup{
+		roles:     map[string]int{},
+		positions: map[string]int{},
+		majors:    map[string]int{},
+		prodis:    map[string]int{},
+	}
+
+	roles, err := GetAllRoles(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		l.roles[strings.ToLower(r.Name)] = r.ID
+	}
+
+	positions, err := GetAllPositions(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range positions {
+		l.positions[strings.ToLower(p.Name)] = p.ID
+	}
+
+	majors, err := GetAllMajors(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range majors {
+		l.majors[strings.ToLower(m.Name)] = m.ID
+	}
+
+	prodis, err := GetAllStudyPrograms(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prodis {
+		l.prodis[strings.ToLower(p.Name)] = p.ID
+	}
+
+	return l, nil
+}
+
+// parsePositions membaca kolom positions dengan format
+// "Jabatan|scope|Nama Scope|tgl_mulai|tgl_selesai", dipisah ";" untuk lebih dari satu jabatan.
+// scope bernilai major, prodi, atau none; tanggal (YYYY-MM-DD) opsional.
+func (l *importLookup) parsePositions(raw string) ([]LecturerPosition, []string) {
+	var result []LecturerPosition
+	var errs []string
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "|")
+		for len(parts) < 5 {
+			parts = append(parts, "")
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		posID, ok := l.positions[strings.ToLower(parts[0])]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("Jabatan '%s' tidak dikenal", parts[0]))
+			continue
+		}
+
+		pos := LecturerPosition{
+			PositionID: posID,
+			StartDate:  GetPtr(parts[3]),
+			EndDate:    GetPtr(parts[4]),
+		}
+
+		switch strings.ToLower(parts[1]) {
+		case "major":
+			id, ok := l.majors[strings.ToLower(parts[2])]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("Jurusan '%s' tidak dikenal", parts[2]))
+				continue
+			}
+			pos.MajorID = sql.NullInt64{Int64: int64(id), Valid: true}
+		case "prodi":
+			id, ok := l.prodis[strings.ToLower(parts[2])]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("Prodi '%s' tidak dikenal", parts[2]))
+				continue
+			}
+			pos.StudyProgramID = sql.NullInt64{Int64: int64(id), Valid: true}
+		case "", "none":
+		default:
+			errs = append(errs, fmt.Sprintf("Scope jabatan '%s' tidak valid", parts[1]))
+			continue
+		}
+
+		result = append(result, pos)
+	}
+
+	return result, errs
+}
+
+// validateImportUniqueness menandai baris yang email/nim/nip/nuptk-nya duplikat di file atau sudah terdaftar.
+func validateImportUniqueness(db *sqlx.DB, rows []ImportRow) error {
+	type key struct {
+		label string
+		value func(f UserForm) *string
+		query string
+	}
+	keys := []key{
+		{"Email", func(f UserForm) *string { return GetPtr(f.Email) }, `SELECT LOWER(email) FROM users WHERE LOWER(email) IN (?)`},
+		{"NIM", func(f UserForm) *string { return f.NIM }, `SELECT LOWER(nim) FROM students WHERE nim IN (?)`},
+		{"NIP", func(f UserForm) *string { return f.NIP }, `SELECT LOWER(nip) FROM lecturers WHERE nip IN (?)`},
+		{"NUPTK", func(f UserForm) *string { return f.NUPTK }, `SELECT LOWER(nuptk) FROM lecturers WHERE nuptk IN (?)`},
+	}
+
+	for _, k := range keys {
+		seen := map[string]int{}
+		var values []string
+
+		for i := range rows {
+			v := k.value(rows[i].Form)
+			if v == nil {
+				continue
+			}
+			lv := strings.ToLower(*v)
+			if first, ok := seen[lv]; ok {
+				rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("%s '%s' duplikat dengan baris %d", k.label, *v, first))
+				continue
+			}
+			seen[lv] = rows[i].Line
+			values = append(values, lv)
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		query, args, err := sqlx.In(k.query, values)
+		if err != nil {
+			return err
+		}
+		var existing []string
+		if err := db.Select(&existing, db.Rebind(query), args...); err != nil {
+			return err
+		}
+
+		taken := map[string]bool{}
+		for _, e := range existing {
+			taken[e] = true
+		}
+		for i := range rows {
+			v := k.value(rows[i].Form)
+			if v != nil && taken[strings.ToLower(*v)] {
+				rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("%s '%s' sudah terdaftar", k.label, *v))
+			}
+		}
+	}
+
+	return nil
+}
+
+func isBlankRecord(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sso-portal-v5/config"
+	"sso-portal-v5/models"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// revocationClaims adalah isi JWT pemberitahuan pencabutan persetujuan, ditandatangani dengan kunci yang sama
+// seperti token login sehingga aplikasi dapat memverifikasinya dengan public key portal.
+type revocationClaims struct {
+	Event string `json:"event"`
+	jwt.RegisteredClaims
+}
+
+// NotifyConsentRevoked memberi tahu aplikasi bahwa user mencabut persetujuannya dengan mengirim POST
+// berisi field revocation_token ke revocation_url aplikasi. Aplikasi tanpa revocation_url dilewati.
+func NotifyConsentRevoked(env *config.Env, app models.Application, userID int) {
+	if !app.RevocationURL.Valid || app.RevocationURL.String == "" {
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, revocationClaims{
+		Event: "consent.revoked",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", userID),
+			Issuer:    config.Issuer,
+			Audience:  jwt.ClaimStrings{app.Slug},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	})
+	tokenString, err := token.SignedString(config.PrivateKey)
+	if err != nil {
+		log.Printf("ERROR [Consent]: gagal membuat token pencabutan untuk %s: %v", app.Slug, err)
+		return
+	}
+
+	go func() {
+		client := &http.Client{Timeout: 5 * time.Second}
+		form := url.Values{"revocation_token": {tokenString}}
+		resp, err := client.Post(app.RevocationURL.String, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		if err != nil {
+			log.Printf("ERROR [Consent]: gagal memberi tahu %s tentang pencabutan user %d: %v", app.Slug, userID, err)
+			return
+		}
+		resp.Body.Close()
+		log.Printf("INFO [Consent]: pencabutan user %d dikirim ke %s, status %d", userID, app.Slug, resp.StatusCode)
+	}()
+}
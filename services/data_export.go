@@ -0,0 +1,133 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sso-portal-v5/config"
+	"sso-portal-v5/models"
+	"time"
+)
+
+const (
+	// DataExportDir adalah folder penyimpanan file export data pribadi. Tidak boleh berada di bawah public/.
+	DataExportDir = "storage/exports"
+	// DataExportTTL adalah lama tautan unduhan export berlaku.
+	DataExportTTL = 48 * time.Hour
+	// dataExportStaleAfter adalah batas waktu export pending sebelum dianggap gagal.
+	dataExportStaleAfter = time.Hour
+)
+
+// GenerateDataExport menyusun arsip ZIP berisi data pribadi user (data.json) di background,
+// lalu memberi tahu user melalui push notification dan email bahwa tautan unduhan sudah siap.
+func GenerateDataExport(env *config.Env, exportID, userID int, token string) {
+	go func() {
+		path, size, err := writeDataExport(env, userID, token)
+		if err != nil {
+			log.Printf("ERROR [Data Export]: gagal membuat export user %d: %v", userID, err)
+			if err := models.MarkDataExportFailed(env.DB, exportID); err != nil {
+				log.Println("ERROR [Data Export]: gagal menandai export gagal:", err)
+			}
+			return
+		}
+
+		expiresAt := time.Now().Add(DataExportTTL)
+		if err := models.MarkDataExportReady(env.DB, exportID, path, size, expiresAt); err != nil {
+			log.Println("ERROR [Data Export]: gagal menyimpan status export:", err)
+			os.Remove(path)
+			return
+		}
+
+		user, err := models.FindUserByID(env.DB, userID)
+		if err != nil {
+			log.Println("ERROR [Data Export]: gagal mengambil data user:", err)
+			return
+		}
+
+		link := env.BaseURL + "/profile/data-export"
+		SendPushNotification(env, userID, "Data Anda Siap Diunduh", "Arsip data pribadi Anda sudah siap diunduh.", link)
+		SendMail(env, user.Email,
+			"Data Anda Siap Diunduh",
+			fmt.Sprintf("Halo %s,\n\nArsip data pribadi yang Anda minta sudah siap. Unduh melalui %s sebelum %s.\nSetelah waktu tersebut tautan tidak berlaku dan Anda perlu meminta export baru.\n", user.Name, link, expiresAt.Format("02-01-2006 15:04")),
+		)
+	}()
+}
+
+func writeDataExport(env *config.Env, userID int, token string) (string, int64, error) {
+	data, err := models.CollectPersonalData(env.DB, userID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := os.MkdirAll(DataExportDir, 0o700); err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(DataExportDir, token+".zip")
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("data.json")
+	if err == nil {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(data)
+	}
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+// PurgeExpiredDataExports menghapus file export yang tautan unduhannya sudah kedaluwarsa, dan menandai gagal
+// export yang tertahan pending (goroutine penyusun hilang karena restart) agar user dapat meminta ulang.
+func PurgeExpiredDataExports(env *config.Env) {
+	stale, err := models.GetStaleDataExports(env.DB, dataExportStaleAfter)
+	if err != nil {
+		log.Println("ERROR [Data Export]: gagal mengambil export pending yang tertahan:", err)
+	}
+	for _, e := range stale {
+		// Hapus arsip setengah jadi jika ada
+		path := filepath.Join(DataExportDir, e.Token+".zip")
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("ERROR [Data Export]: gagal menghapus %s: %v", path, err)
+		}
+		if err := models.MarkDataExportFailed(env.DB, e.ID); err != nil {
+			log.Println("ERROR [Data Export]: gagal menandai export gagal:", err)
+		}
+	}
+
+	exports, err := models.GetExpiredDataExports(env.DB)
+	if err != nil {
+		log.Println("ERROR [Data Export]: gagal mengambil export kedaluwarsa:", err)
+		return
+	}
+
+	for _, e := range exports {
+		if err := os.Remove(e.FilePath.String); err != nil && !os.IsNotExist(err) {
+			log.Printf("ERROR [Data Export]: gagal menghapus %s: %v", e.FilePath.String, err)
+			continue
+		}
+		if err := models.ClearDataExportFile(env.DB, e.ID); err != nil {
+			log.Println("ERROR [Data Export]: gagal memperbarui export:", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sso-portal-v5/config"
+	"sso-portal-v5/models"
+	"sync"
+	"time"
+)
+
+const (
+	// healthCheckTimeout adalah batas waktu satu pemeriksaan aplikasi.
+	healthCheckTimeout = 10 * time.Second
+	// healthCheckWorkers membatasi jumlah aplikasi yang diperiksa bersamaan.
+	healthCheckWorkers = 5
+	// healthHistoryDays adalah lama riwayat pemeriksaan disimpan.
+	healthHistoryDays = 30
+)
+
+// CheckApplicationHealth memeriksa semua aplikasi dan mencatat hasilnya. Aplikasi dianggap up jika
+// merespons dengan status di bawah 500. Admin aplikasi diberi notifikasi saat status berubah menjadi down atau pulih.
+func CheckApplicationHealth(env *config.Env) {
+	targets, err := models.GetHealthCheckTargets(env.DB)
+	if err != nil {
+		log.Println("ERROR [Health Check]: gagal mengambil daftar aplikasi:", err)
+		return
+	}
+
+	client := &http.Client{Timeout: healthCheckTimeout}
+	jobs := make(chan models.HealthCheckTarget)
+	var wg sync.WaitGroup
+
+	for i := 0; i < healthCheckWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				check := probeApplication(client, target)
+				if err := models.RecordHealthCheck(env.DB, check); err != nil {
+					log.Printf("ERROR [Health Check]: gagal menyimpan hasil %s: %v", target.Name, err)
+					continue
+				}
+				if target.HealthStatus != "unknown" && target.HealthStatus != check.Status {
+					notifyHealthChange(env, target, check)
+				}
+			}
+		}()
+	}
+
+	for _, target := range targets {
+		jobs <- target
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func probeApplication(client *http.Client, target models.HealthCheckTarget) models.HealthCheck {
+	check := models.HealthCheck{ApplicationID: target.ID, Status: "down"}
+
+	start := time.Now()
+	req, err := http.NewRequest("GET", target.URL, nil)
+	if err != nil {
+		check.Error = sql.NullString{String: truncate(err.Error(), 255), Valid: true}
+		return check
+	}
+	req.Header.Set("User-Agent", "sso-portal-v5 health checker")
+
+	resp, err := client.Do(req)
+	check.ResponseMS = sql.NullInt64{Int64: time.Since(start).Milliseconds(), Valid: true}
+	if err != nil {
+		check.Error = sql.NullString{String: truncate(err.Error(), 255), Valid: true}
+		return check
+	}
+	resp.Body.Close()
+
+	check.HTTPStatus = sql.NullInt64{Int64: int64(resp.StatusCode), Valid: true}
+	if resp.StatusCode < 500 {
+		check.Status = "up"
+	}
+	return check
+}
+
+func notifyHealthChange(env *config.Env, target models.HealthCheckTarget, check models.HealthCheck) {
+	adminIDs, err := models.GetUserIDsWithPermission(env.DB, models.PermAppsWrite)
+	if err != nil {
+		log.Println("ERROR [Health Check]: gagal mengambil daftar admin:", err)
+		return
+	}
+
+	title := "Aplikasi Tidak Dapat Diakses"
+	message := fmt.Sprintf("%s tidak merespons dengan normal.", target.Name)
+	if check.Status == "up" {
+		title = "Aplikasi Kembali Normal"
+		message = fmt.Sprintf("%s kembali dapat diakses.", target.Name)
+	}
+	log.Printf("INFO [Health Check]: status %s berubah menjadi %s", target.Name, check.Status)
+
+	for _, adminID := range adminIDs {
+		SendPushNotification(env, adminID, title, message, fmt.Sprintf("%s/admin/application/detail/%d", env.BaseURL, target.ID))
+	}
+}
+
+// PurgeHealthHistory menghapus riwayat pemeriksaan yang sudah melewati masa simpan.
+func PurgeHealthHistory(env *config.Env) {
+	deleted, err := models.PurgeHealthChecks(env.DB, healthHistoryDays)
+	if err != nil {
+		log.Println("ERROR [Health Check]: gagal menghapus riwayat lama:", err)
+		return
+	}
+	log.Printf("INFO [Health Check]: %d riwayat pemeriksaan lama dihapus", deleted)
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"net/smtp"
+	"sso-portal-v5/config"
+	"strings"
+)
+
+// SendMail mengirim email teks biasa melalui SMTP. Jika SMTP_HOST kosong, pengiriman dilewati.
+func SendMail(env *config.Env, to, subject, body string) {
+	if env.SMTPHost == "" {
+		log.Printf("INFO [Send Mail]: SMTP tidak dikonfigurasi, email ke %s dilewati", to)
+		return
+	}
+
+	port := env.SMTPPort
+	if port == "" {
+		port = "587"
+	}
+
+	from := env.SMTPFrom
+	if from == "" {
+		from = env.SMTPUser
+	}
+
+	headers := []string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"UTF-8\"",
+	}
+	msg := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	var auth smtp.Auth
+	if env.SMTPUser != "" {
+		auth = smtp.PlainAuth("", env.SMTPUser, env.SMTPPassword, env.SMTPHost)
+	}
+
+	// Envelope sender mengikuti header From (SMTP_FROM bisa berbentuk "Nama <alamat>")
+	sender := from
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		sender = parsed.Address
+	}
+
+	addr := fmt.Sprintf("%s:%s", env.SMTPHost, port)
+	if err := smtp.SendMail(addr, auth, sender, []string{to}, []byte(msg)); err != nil {
+		log.Println("ERROR [Send Mail]: ", err)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sso-portal-v5/config"
+	"sso-portal-v5/models"
+)
+
+// positionExpiryReminderDays adalah jumlah hari sebelum end_date saat pengingat dikirim.
+const positionExpiryReminderDays = 14
+
+// CheckPositionExpiry mengingatkan dosen dan admin tentang jabatan yang akan berakhir,
+// lalu menandai jabatan yang end_date-nya sudah lewat sebagai berakhir.
+func CheckPositionExpiry(env *config.Env) {
+	adminIDs, err := models.GetUserIDsWithPermission(env.DB, models.PermUsersWrite)
+	if err != nil {
+		log.Println("ERROR [Position Expiry]: gagal mengambil daftar admin:", err)
+	}
+
+	expiring, err := models.GetPositionsExpiringWithin(env.DB, positionExpiryReminderDays)
+	if err != nil {
+		log.Println("ERROR [Position Expiry]: gagal mengambil jabatan yang akan berakhir:", err)
+	}
+
+	for _, p := range expiring {
+		SendPushNotification(env, p.UserID,
+			"Masa Jabatan Akan Berakhir",
+			fmt.Sprintf("Jabatan %s Anda berakhir pada %s.", p.Label(), p.EndDate),
+			env.BaseURL,
+		)
+		SendMail(env, p.UserEmail,
+			"Masa Jabatan Akan Berakhir",
+			fmt.Sprintf("Halo %s,\n\nJabatan %s Anda tercatat berakhir pada %s. Setelah tanggal tersebut, akses aplikasi yang terkait jabatan ini akan dicabut.\nHubungi administrator jika masa jabatan diperpanjang.\n", p.UserName, p.Label(), p.EndDate),
+		)
+		for _, adminID := range adminIDs {
+			SendPushNotification(env, adminID,
+				"Masa Jabatan Akan Berakhir",
+				fmt.Sprintf("Jabatan %s milik %s berakhir pada %s.", p.Label(), p.UserName, p.EndDate),
+				fmt.Sprintf("%s/admin/user/detail/%d", env.BaseURL, p.UserID),
+			)
+		}
+
+		if err := models.MarkPositionExpiryNotified(env.DB, p.ID); err != nil {
+			log.Println("ERROR [Position Expiry]: gagal menandai pengingat:", err)
+		}
+	}
+
+	lapsed, err := models.GetLapsedPositions(env.DB)
+	if err != nil {
+		log.Println("ERROR [Position Expiry]: gagal mengambil jabatan yang sudah berakhir:", err)
+		return
+	}
+
+	for _, p := range lapsed {
+		if err := models.MarkPositionEnded(env.DB, p.ID); err != nil {
+			log.Println("ERROR [Position Expiry]: gagal menandai jabatan berakhir:", err)
+			continue
+		}
+		log.Printf("INFO [Position Expiry]: jabatan %s milik %s berakhir per %s", p.Label(), p.UserName, p.EndDate)
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"log"
+	"sso-portal-v5/config"
+
+	"github.com/robfig/cron/v3"
+)
+
+// StartScheduler mendaftarkan job terjadwal lalu menjalankannya di background.
+func StartScheduler(env *config.Env) *cron.Cron {
+	c := cron.New()
+
+	// Setiap hari pukul 06:00: ingatkan jabatan yang akan berakhir dan tandai yang sudah lewat
+	if _, err := c.AddFunc("0 6 * * *", func() { CheckPositionExpiry(env) }); err != nil {
+		log.Println("ERROR [Scheduler]: gagal mendaftarkan job masa jabatan:", err)
+	}
+
+	// Setiap 5 menit: periksa status aplikasi klien
+	if _, err := c.AddFunc("*/5 * * * *", func() { CheckApplicationHealth(env) }); err != nil {
+		log.Println("ERROR [Scheduler]: gagal mendaftarkan job health check:", err)
+	}
+
+	// Setiap hari pukul 03:00: hapus riwayat health check lama
+	if _, err := c.AddFunc("0 3 * * *", func() { PurgeHealthHistory(env) }); err != nil {
+		log.Println("ERROR [Scheduler]: gagal mendaftarkan job pembersihan riwayat health check:", err)
+	}
+
+	// Setiap jam: hapus file export data pribadi yang tautannya sudah kedaluwarsa dan gagalkan export yang tertahan
+	if _, err := c.AddFunc("0 * * * *", func() { PurgeExpiredDataExports(env) }); err != nil {
+		log.Println("ERROR [Scheduler]: gagal mendaftarkan job pembersihan export data:", err)
+	}
+
+	c.Start()
+	return c
+}
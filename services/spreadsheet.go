@@ -0,0 +1,38 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ReadSpreadsheet membaca file CSV atau XLSX (sheet pertama) menjadi slice baris.
+func ReadSpreadsheet(file io.Reader, filename string) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+
+	case ".xlsx":
+		f, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("file XLSX tidak memiliki sheet")
+		}
+		return f.GetRows(sheets[0])
+
+	default:
+		return nil, fmt.Errorf("format file tidak didukung, gunakan .csv atau .xlsx")
+	}
+}